@@ -0,0 +1,149 @@
+package sample
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestRunBatchPartialFailure checks that one failing ID doesn't discard the
+// results of the others that succeeded, and that ContinueOnError controls
+// whether the rest of the batch still runs.
+func TestRunBatchPartialFailure(t *testing.T) {
+	ids := []string{"a", "b", "c"}
+	op := func(ctx context.Context, id string) (*Resource, error) {
+		if id == "b" {
+			return nil, errors.New("boom")
+		}
+		return &Resource{ID: id}, nil
+	}
+
+	t.Run("stopsAfterFirstError", func(t *testing.T) {
+		results, err := runBatch(context.Background(), ids, &BatchOptions{Concurrency: 1}, op)
+		if err == nil {
+			t.Fatal("expected an error, got nil")
+		}
+		if results[0].Status != ResultStatusSuccess || results[0].Value.ID != "a" {
+			t.Errorf("results[0] = %+v, want success for %q", results[0], "a")
+		}
+		if results[1].Status != ResultStatusFailed {
+			t.Errorf("results[1].Status = %v, want %v", results[1].Status, ResultStatusFailed)
+		}
+	})
+
+	t.Run("continueOnError", func(t *testing.T) {
+		results, err := runBatch(context.Background(), ids, &BatchOptions{Concurrency: 3, ContinueOnError: true}, op)
+		if err != nil {
+			t.Fatalf("expected nil error with ContinueOnError, got %v", err)
+		}
+		if len(results) != len(ids) {
+			t.Fatalf("got %d results, want %d", len(results), len(ids))
+		}
+		for i, id := range ids {
+			wantStatus := ResultStatusSuccess
+			if id == "b" {
+				wantStatus = ResultStatusFailed
+			}
+			if results[i].Status != wantStatus {
+				t.Errorf("results[%d] (%s) status = %v, want %v", i, id, results[i].Status, wantStatus)
+			}
+		}
+	})
+}
+
+// TestRunBatchPreservesOrder checks that results land at the same index as
+// their input ID regardless of completion order, since goroutines racing
+// over a worker pool complete out of order.
+func TestRunBatchPreservesOrder(t *testing.T) {
+	ids := []string{"slow", "fast", "medium"}
+	delays := map[string]time.Duration{"slow": 30 * time.Millisecond, "fast": 0, "medium": 15 * time.Millisecond}
+	op := func(ctx context.Context, id string) (*Resource, error) {
+		time.Sleep(delays[id])
+		return &Resource{ID: id}, nil
+	}
+
+	results, err := runBatch(context.Background(), ids, &BatchOptions{Concurrency: 3}, op)
+	if err != nil {
+		t.Fatalf("runBatch: %v", err)
+	}
+	for i, id := range ids {
+		if results[i].Value.ID != id {
+			t.Errorf("results[%d].Value.ID = %q, want %q", i, results[i].Value.ID, id)
+		}
+	}
+}
+
+// TestRunBatchCancellation checks that cancelling ctx stops dispatching new
+// work promptly: with ContinueOnError false, an early failure should cancel
+// the shared context and the remaining in-flight/not-yet-started items
+// should observe ctx.Err() rather than running to completion.
+func TestRunBatchCancellation(t *testing.T) {
+	const n = 20
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("id-%d", i)
+	}
+
+	var started, ranToCompletion int64
+	op := func(ctx context.Context, id string) (*Resource, error) {
+		if id == "id-0" {
+			return nil, errors.New("boom")
+		}
+		atomic.AddInt64(&started, 1)
+		select {
+		case <-time.After(50 * time.Millisecond):
+			atomic.AddInt64(&ranToCompletion, 1)
+			return &Resource{ID: id}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	results, err := runBatch(context.Background(), ids, &BatchOptions{Concurrency: 4}, op)
+	if err == nil {
+		t.Fatal("expected an error from the id-0 failure")
+	}
+	if atomic.LoadInt64(&ranToCompletion) == atomic.LoadInt64(&started) && atomic.LoadInt64(&started) == int64(n-1) {
+		t.Error("every dispatched item ran to completion; cancellation did not stop in-flight work")
+	}
+
+	cancelled := 0
+	for _, r := range results {
+		if r.Status == ResultStatusFailed && r.Error == context.Canceled.Error() {
+			cancelled++
+		}
+	}
+	if cancelled == 0 {
+		t.Error("expected at least one result to report context.Canceled")
+	}
+}
+
+// BenchmarkRunBatchConcurrency demonstrates throughput scaling with
+// concurrency: each op simulates a fixed-latency network call, so wall time
+// should shrink roughly in proportion to Concurrency until it saturates
+// len(ids).
+func BenchmarkRunBatchConcurrency(b *testing.B) {
+	const opLatency = 2 * time.Millisecond
+	ids := make([]string, 64)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("id-%d", i)
+	}
+	op := func(ctx context.Context, id string) (*Resource, error) {
+		time.Sleep(opLatency)
+		return &Resource{ID: id}, nil
+	}
+
+	for _, concurrency := range []int{1, 4, 16, 64} {
+		concurrency := concurrency
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := runBatch(context.Background(), ids, &BatchOptions{Concurrency: concurrency}, op); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
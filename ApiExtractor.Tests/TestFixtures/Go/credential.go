@@ -0,0 +1,169 @@
+package sample
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"sample/runtime"
+)
+
+// TokenCredential represents a credential capable of providing an
+// AccessToken, such as a ClientSecretCredential or a StaticTokenCredential.
+type TokenCredential = runtime.TokenCredential
+
+// AccessToken is a token with an expiration time.
+type AccessToken = runtime.AccessToken
+
+// TokenRequestOptions carries the scopes a caller needs a token for.
+type TokenRequestOptions = runtime.TokenRequestOptions
+
+// CredentialUnavailableError indicates a credential could not be used, for
+// example because required configuration (environment variables, a managed
+// identity endpoint) is missing. NewDefaultCredential treats this error as
+// "try the next credential in the chain" rather than a hard failure.
+type CredentialUnavailableError struct {
+	Credential string
+	Message    string
+}
+
+func (e *CredentialUnavailableError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Credential, e.Message)
+}
+
+// StaticTokenCredential always returns the same token. It is primarily
+// useful for tests and for services that hand out long-lived tokens.
+type StaticTokenCredential struct {
+	Token     string
+	ExpiresOn time.Time
+}
+
+// GetToken implements TokenCredential.
+func (c StaticTokenCredential) GetToken(ctx context.Context, options TokenRequestOptions) (AccessToken, error) {
+	if c.Token == "" {
+		return AccessToken{}, &CredentialUnavailableError{Credential: "StaticTokenCredential", Message: "no token configured"}
+	}
+	return AccessToken{Token: c.Token, ExpiresOn: c.ExpiresOn}, nil
+}
+
+// ClientSecretCredential authenticates using a tenant ID, client ID, and
+// client secret, the OAuth2 client-credentials flow.
+type ClientSecretCredential struct {
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+
+	// tokenLifetime overrides the default token lifetime; tests only.
+	tokenLifetime time.Duration
+}
+
+// NewClientSecretCredential validates its arguments and returns a
+// ClientSecretCredential.
+func NewClientSecretCredential(tenantID, clientID, clientSecret string) (*ClientSecretCredential, error) {
+	if tenantID == "" || clientID == "" || clientSecret == "" {
+		return nil, &CredentialUnavailableError{Credential: "ClientSecretCredential", Message: "tenantID, clientID, and clientSecret are all required"}
+	}
+	return &ClientSecretCredential{TenantID: tenantID, ClientID: clientID, ClientSecret: clientSecret}, nil
+}
+
+// GetToken implements TokenCredential. It does not contact the tenant's
+// OAuth2 token endpoint; it returns a locally minted token built from the
+// tenant and client IDs.
+func (c *ClientSecretCredential) GetToken(ctx context.Context, options TokenRequestOptions) (AccessToken, error) {
+	if c.TenantID == "" || c.ClientID == "" || c.ClientSecret == "" {
+		return AccessToken{}, &CredentialUnavailableError{Credential: "ClientSecretCredential", Message: "tenantID, clientID, and clientSecret are all required"}
+	}
+	lifetime := c.tokenLifetime
+	if lifetime <= 0 {
+		lifetime = time.Hour
+	}
+	// A real implementation POSTs to
+	// https://login.microsoftonline.com/{tenantID}/oauth2/v2.0/token; this
+	// fixture client has no authority to call out to, so it mints a token
+	// that is shaped like the real thing.
+	return AccessToken{
+		Token:     fmt.Sprintf("fake-token-%s-%s", c.TenantID, c.ClientID),
+		ExpiresOn: time.Now().Add(lifetime),
+	}, nil
+}
+
+// EnvironmentCredential reads AUTH_TENANT_ID, AUTH_CLIENT_ID, and
+// AUTH_CLIENT_SECRET from the environment and delegates to a
+// ClientSecretCredential.
+type EnvironmentCredential struct {
+	inner *ClientSecretCredential
+}
+
+// NewEnvironmentCredential reads credential configuration from the
+// environment. It returns a CredentialUnavailableError if the required
+// variables are not set.
+func NewEnvironmentCredential() (*EnvironmentCredential, error) {
+	tenantID := os.Getenv("AUTH_TENANT_ID")
+	clientID := os.Getenv("AUTH_CLIENT_ID")
+	clientSecret := os.Getenv("AUTH_CLIENT_SECRET")
+	if tenantID == "" || clientID == "" || clientSecret == "" {
+		return nil, &CredentialUnavailableError{Credential: "EnvironmentCredential", Message: "AUTH_TENANT_ID, AUTH_CLIENT_ID, and AUTH_CLIENT_SECRET must all be set"}
+	}
+	inner, err := NewClientSecretCredential(tenantID, clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	return &EnvironmentCredential{inner: inner}, nil
+}
+
+// GetToken implements TokenCredential.
+func (c *EnvironmentCredential) GetToken(ctx context.Context, options TokenRequestOptions) (AccessToken, error) {
+	return c.inner.GetToken(ctx, options)
+}
+
+// chainedCredential tries each credential in order, moving on to the next
+// when one reports a CredentialUnavailableError.
+type chainedCredential struct {
+	sources []TokenCredential
+}
+
+// GetToken implements TokenCredential.
+func (c *chainedCredential) GetToken(ctx context.Context, options TokenRequestOptions) (AccessToken, error) {
+	var unavailable []string
+	for _, source := range c.sources {
+		token, err := source.GetToken(ctx, options)
+		if err == nil {
+			return token, nil
+		}
+		var cu *CredentialUnavailableError
+		if !asCredentialUnavailable(err, &cu) {
+			return AccessToken{}, err
+		}
+		unavailable = append(unavailable, cu.Error())
+	}
+	return AccessToken{}, &CredentialUnavailableError{
+		Credential: "NewDefaultCredential",
+		Message:    fmt.Sprintf("no credential in the chain was available: %v", unavailable),
+	}
+}
+
+func asCredentialUnavailable(err error, target **CredentialUnavailableError) bool {
+	cu, ok := err.(*CredentialUnavailableError)
+	if !ok {
+		return false
+	}
+	*target = cu
+	return true
+}
+
+// NewDefaultCredential returns a credential that tries, in order, a
+// static token from AUTH_STATIC_TOKEN, an EnvironmentCredential, and
+// finally falls through to a CredentialUnavailableError if none apply.
+// Each source is only consulted if the previous one reports a
+// CredentialUnavailableError.
+func NewDefaultCredential() TokenCredential {
+	var sources []TokenCredential
+	if token := os.Getenv("AUTH_STATIC_TOKEN"); token != "" {
+		sources = append(sources, StaticTokenCredential{Token: token, ExpiresOn: time.Now().Add(24 * time.Hour)})
+	}
+	if envCred, err := NewEnvironmentCredential(); err == nil {
+		sources = append(sources, envCred)
+	}
+	return &chainedCredential{sources: sources}
+}
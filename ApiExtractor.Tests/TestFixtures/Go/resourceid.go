@@ -0,0 +1,188 @@
+package sample
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ResourceType identifies the provider namespace and type chain of a
+// resource, e.g. "Microsoft.Sample/widgets" or the nested
+// "Microsoft.Sample/widgets/parts" for a child resource.
+type ResourceType struct {
+	// Namespace is the resource provider namespace, e.g. "Microsoft.Sample".
+	Namespace string
+	// Types is the type chain, e.g. ["widgets"] or ["widgets", "parts"]
+	// for a nested child resource.
+	Types []string
+}
+
+// String returns the canonical "Namespace/type1/type2" form.
+func (t ResourceType) String() string {
+	if t.Namespace == "" {
+		return strings.Join(t.Types, "/")
+	}
+	return t.Namespace + "/" + strings.Join(t.Types, "/")
+}
+
+// Equals reports whether t and other refer to the same resource type,
+// ignoring case as ARM does for provider and type names.
+func (t ResourceType) Equals(other ResourceType) bool {
+	return strings.EqualFold(t.String(), other.String())
+}
+
+// ResourceID is a parsed ARM-style resource identifier:
+//
+//	/subscriptions/{subscriptionID}/resourceGroups/{resourceGroupName}/providers/{namespace}/{type}/{name}[/{childType}/{childName}...]
+type ResourceID struct {
+	// SubscriptionID is the subscription segment.
+	SubscriptionID string
+	// ResourceGroupName is the resource group segment.
+	ResourceGroupName string
+	// Provider is the resource provider namespace, e.g. "Microsoft.Sample".
+	Provider string
+	// ResourceType is the (possibly nested) type of the resource.
+	ResourceType ResourceType
+	// Name is the resource's own name (the last name segment).
+	Name string
+	// Parent is the resource this one is nested under, or nil for a
+	// top-level resource.
+	Parent *ResourceID
+	// Location is an optional region, populated only when the caller
+	// supplies it out of band; ParseResourceID never sets it.
+	Location string
+}
+
+// String returns the canonical, round-trippable form of the ID.
+func (id *ResourceID) String() string {
+	if id == nil {
+		return ""
+	}
+	// Walk from this resource up to the root, then emit type/name pairs
+	// root-first.
+	var chain []*ResourceID
+	for cur := id; cur != nil; cur = cur.Parent {
+		chain = append(chain, cur)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "/subscriptions/%s/resourceGroups/%s/providers/%s",
+		id.SubscriptionID, id.ResourceGroupName, id.Provider)
+	for i := len(chain) - 1; i >= 0; i-- {
+		node := chain[i]
+		typeName := node.ResourceType.Types[len(node.ResourceType.Types)-1]
+		fmt.Fprintf(&b, "/%s/%s", typeName, node.Name)
+	}
+	return b.String()
+}
+
+// Equals reports whether id and other refer to the same resource.
+func (id *ResourceID) Equals(other *ResourceID) bool {
+	if id == nil || other == nil {
+		return id == other
+	}
+	return strings.EqualFold(id.String(), other.String())
+}
+
+// ParseResourceID parses an ARM-style resource ID of the form
+// "/subscriptions/{}/resourceGroups/{}/providers/{namespace}/{type}/{name}[/{childType}/{childName}...]",
+// linking parent/child resources for nested (child) resources.
+func ParseResourceID(raw string) (*ResourceID, error) {
+	if raw == "" || raw[0] != '/' {
+		return nil, fmt.Errorf("sample: resource id must be an absolute path, got %q", raw)
+	}
+	segments := strings.Split(strings.Trim(raw, "/"), "/")
+	if len(segments) < 7 {
+		return nil, fmt.Errorf("sample: resource id %q has too few segments", raw)
+	}
+	if len(segments)%2 != 0 {
+		return nil, fmt.Errorf("sample: resource id %q has an unpaired trailing segment", raw)
+	}
+
+	idx := 0
+	next := func(expectPrefix string) (string, error) {
+		if idx+1 >= len(segments) {
+			return "", fmt.Errorf("sample: resource id %q ended while looking for %s", raw, expectPrefix)
+		}
+		if expectPrefix != "" && !strings.EqualFold(segments[idx], expectPrefix) {
+			return "", fmt.Errorf("sample: resource id %q expected %q at position %d, got %q", raw, expectPrefix, idx, segments[idx])
+		}
+		value := segments[idx+1]
+		idx += 2
+		return value, nil
+	}
+
+	subID, err := next("subscriptions")
+	if err != nil {
+		return nil, err
+	}
+	rg, err := next("resourceGroups")
+	if err != nil {
+		return nil, err
+	}
+	provider, err := next("providers")
+	if err != nil {
+		return nil, err
+	}
+
+	var chain []string
+	var names []string
+	for idx < len(segments) {
+		typeName, err := next("")
+		if err != nil {
+			return nil, err
+		}
+		nameSeg, err := next("")
+		if err != nil {
+			return nil, fmt.Errorf("sample: resource id %q has type %q with no name", raw, typeName)
+		}
+		chain = append(chain, typeName)
+		names = append(names, nameSeg)
+	}
+
+	var parent *ResourceID
+	for i := range chain {
+		current := &ResourceID{
+			SubscriptionID:    subID,
+			ResourceGroupName: rg,
+			Provider:          provider,
+			ResourceType:      ResourceType{Namespace: provider, Types: append([]string{}, chain[:i+1]...)},
+			Name:              names[i],
+			Parent:            parent,
+		}
+		parent = current
+	}
+	return parent, nil
+}
+
+// GetResourceByID retrieves a resource by ID. id may be a plain resource ID
+// string or a *ResourceID, matching the flexibility of the ARM ecosystem's
+// SDKs that accept either form.
+func (c *SampleClient) GetResourceByID(ctx context.Context, id any) (*Resource, error) {
+	resourceID, err := coerceResourceID(id)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetResource(ctx, resourceID.String())
+}
+
+// DeleteResourceByID deletes a resource by ID. id may be a plain resource
+// ID string or a *ResourceID.
+func (c *SampleClient) DeleteResourceByID(ctx context.Context, id any) error {
+	resourceID, err := coerceResourceID(id)
+	if err != nil {
+		return err
+	}
+	return c.DeleteResource(ctx, resourceID.String())
+}
+
+func coerceResourceID(id any) (*ResourceID, error) {
+	switch v := id.(type) {
+	case *ResourceID:
+		return v, nil
+	case string:
+		return ParseResourceID(v)
+	default:
+		return nil, fmt.Errorf("sample: id must be a string or *ResourceID, got %T", id)
+	}
+}
@@ -0,0 +1,59 @@
+package runtime
+
+import "context"
+
+// PagerResponse is a single page of results, along with whatever the
+// service returned to let the caller fetch the next page.
+type PagerResponse[T any] struct {
+	// Values are the items on this page.
+	Values []T
+	// NextLink is an opaque URL for the next page, if the service paginates
+	// by link.
+	NextLink string
+	// ContinuationToken is an opaque token for the next page, if the
+	// service paginates by token instead of link.
+	ContinuationToken string
+}
+
+// Pager iterates the pages of a paginated list operation.
+type Pager[T any] struct {
+	fetcher func(ctx context.Context, token *string) (PagerResponse[T], error)
+
+	current *string
+	more    bool
+}
+
+// NewPager creates a Pager that lazily calls fetcher once per page. fetcher
+// receives nil on the first call, and thereafter whatever continuation
+// value (link or token) the previous PagerResponse reported.
+func NewPager[T any](fetcher func(ctx context.Context, token *string) (PagerResponse[T], error)) *Pager[T] {
+	return &Pager[T]{fetcher: fetcher, more: true}
+}
+
+// More reports whether another page is available. Callers should check
+// More before calling NextPage, and stop once it returns false.
+func (p *Pager[T]) More() bool {
+	return p.more
+}
+
+// NextPage fetches the next page of results.
+func (p *Pager[T]) NextPage(ctx context.Context) (PagerResponse[T], error) {
+	resp, err := p.fetcher(ctx, p.current)
+	if err != nil {
+		return PagerResponse[T]{}, err
+	}
+	switch {
+	case resp.ContinuationToken != "":
+		token := resp.ContinuationToken
+		p.current = &token
+		p.more = true
+	case resp.NextLink != "":
+		link := resp.NextLink
+		p.current = &link
+		p.more = true
+	default:
+		p.current = nil
+		p.more = false
+	}
+	return resp, nil
+}
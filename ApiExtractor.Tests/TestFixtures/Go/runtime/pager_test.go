@@ -0,0 +1,87 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+)
+
+// TestPagerWalksMultiPageResultSet drives a Pager against a mock fetcher
+// that pages by ContinuationToken, the same way a real service would, and
+// checks that More/NextPage walk every page in order and stop correctly.
+func TestPagerWalksMultiPageResultSet(t *testing.T) {
+	mockPages := map[string]PagerResponse[int]{
+		"":      {Values: []int{1, 2}, ContinuationToken: "page2"},
+		"page2": {Values: []int{3, 4}, ContinuationToken: "page3"},
+		"page3": {Values: []int{5}},
+	}
+
+	var calls []string
+	pager := NewPager(func(ctx context.Context, token *string) (PagerResponse[int], error) {
+		key := ""
+		if token != nil {
+			key = *token
+		}
+		calls = append(calls, key)
+		return mockPages[key], nil
+	})
+
+	var got []int
+	for pager.More() {
+		resp, err := pager.NextPage(context.Background())
+		if err != nil {
+			t.Fatalf("NextPage: %v", err)
+		}
+		got = append(got, resp.Values...)
+	}
+
+	wantCalls := []string{"", "page2", "page3"}
+	if len(calls) != len(wantCalls) {
+		t.Fatalf("fetcher called %d times, want %d: %v", len(calls), len(wantCalls), calls)
+	}
+	for i, c := range wantCalls {
+		if calls[i] != c {
+			t.Errorf("call %d token = %q, want %q", i, calls[i], c)
+		}
+	}
+
+	want := []int{1, 2, 3, 4, 5}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i, v := range want {
+		if got[i] != v {
+			t.Errorf("item %d = %d, want %d", i, got[i], v)
+		}
+	}
+
+	if pager.More() {
+		t.Error("More() returned true after the last page")
+	}
+}
+
+// TestPagerSinglePage checks the no-pagination case: a fetcher that never
+// sets NextLink/ContinuationToken should stop the pager after one page.
+func TestPagerSinglePage(t *testing.T) {
+	calls := 0
+	pager := NewPager(func(ctx context.Context, token *string) (PagerResponse[int], error) {
+		calls++
+		return PagerResponse[int]{Values: []int{42}}, nil
+	})
+
+	if !pager.More() {
+		t.Fatal("More() returned false before the first call")
+	}
+	resp, err := pager.NextPage(context.Background())
+	if err != nil {
+		t.Fatalf("NextPage: %v", err)
+	}
+	if len(resp.Values) != 1 || resp.Values[0] != 42 {
+		t.Fatalf("got %v, want [42]", resp.Values)
+	}
+	if pager.More() {
+		t.Error("More() returned true after a single-page result")
+	}
+	if calls != 1 {
+		t.Errorf("fetcher called %d times, want 1", calls)
+	}
+}
@@ -0,0 +1,322 @@
+// Package runtime provides the low-level HTTP pipeline shared by generated
+// clients: an ordered chain of policies that every request flows through on
+// the way out and every response flows through on the way back.
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TokenRequestOptions carries the scopes a caller needs a token for.
+type TokenRequestOptions struct {
+	// Scopes are the permission scopes required for the token.
+	Scopes []string
+}
+
+// AccessToken is a token with an expiration time.
+type AccessToken struct {
+	// Token is the token value.
+	Token string
+	// ExpiresOn is when the token expires.
+	ExpiresOn time.Time
+}
+
+// TokenCredential represents a credential capable of providing an AccessToken.
+type TokenCredential interface {
+	// GetToken requests an access token for the given scopes.
+	GetToken(ctx context.Context, options TokenRequestOptions) (AccessToken, error)
+}
+
+// Request is a request flowing through a Pipeline.
+type Request struct {
+	// Raw is the underlying HTTP request.
+	Raw *http.Request
+
+	policies []Policy
+	cur      int
+}
+
+// Response is the result of sending a Request through a Pipeline.
+type Response struct {
+	// Raw is the underlying HTTP response.
+	Raw *http.Response
+}
+
+// Next calls the next policy in the chain. Policies call this exactly once
+// to delegate to the rest of the pipeline.
+func (req *Request) Next() (*Response, error) {
+	if req.cur >= len(req.policies) {
+		return nil, fmt.Errorf("runtime: no more policies, pipeline is misconfigured")
+	}
+	policy := req.policies[req.cur]
+	req.cur++
+	return policy.Do(req)
+}
+
+// Clone returns a shallow copy of req with a cloned Raw request, suitable
+// for retrying without mutating the original.
+func (req *Request) Clone(ctx context.Context) *Request {
+	return &Request{
+		Raw:      req.Raw.Clone(ctx),
+		policies: req.policies,
+		cur:      req.cur,
+	}
+}
+
+// Policy processes a Request, optionally inspecting or mutating it, and
+// must call req.Next() to continue the pipeline unless it short-circuits
+// with its own Response or error.
+type Policy interface {
+	Do(req *Request) (*Response, error)
+}
+
+// PolicyFunc adapts a plain function to the Policy interface.
+type PolicyFunc func(req *Request) (*Response, error)
+
+// Do implements Policy.
+func (f PolicyFunc) Do(req *Request) (*Response, error) {
+	return f(req)
+}
+
+// Pipeline is an ordered chain of policies terminated by a transport that
+// actually sends the request over the wire.
+type Pipeline struct {
+	policies []Policy
+}
+
+// transportPolicy is always the last policy in the chain; it performs the
+// real network round trip.
+type transportPolicy struct {
+	transport http.RoundTripper
+}
+
+func (p transportPolicy) Do(req *Request) (*Response, error) {
+	resp, err := p.transport.RoundTrip(req.Raw)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{Raw: resp}, nil
+}
+
+// NewPipeline builds a Pipeline from perCall policies, perRetry policies,
+// and a transport. perRetry policies run once per retry attempt (inside the
+// retry policy, if one is present in perCall); perCall policies run once
+// per logical request.
+func NewPipeline(transport http.RoundTripper, perCall []PerCallPolicy, perRetry []PerRetryPolicy) *Pipeline {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	policies := make([]Policy, 0, len(perCall)+len(perRetry)+1)
+	for _, p := range perCall {
+		policies = append(policies, p)
+	}
+	policies = append(policies, retryPolicy{perRetry: perRetry})
+	policies = append(policies, transportPolicy{transport: transport})
+	return &Pipeline{policies: policies}
+}
+
+// Do sends req through the pipeline and returns the final Response.
+func (p *Pipeline) Do(ctx context.Context, req *http.Request) (*Response, error) {
+	r := &Request{Raw: req.WithContext(ctx), policies: p.policies}
+	return r.Next()
+}
+
+// PerCallPolicy is a Policy that runs once per logical call, before retries
+// are applied.
+type PerCallPolicy = Policy
+
+// PerRetryPolicy is a Policy that runs on every retry attempt, after the
+// retry policy has decided to try again.
+type PerRetryPolicy = Policy
+
+// RetryOptions configures the built-in retry policy.
+type RetryOptions struct {
+	// MaxRetries is the maximum number of retry attempts. Default: 3.
+	MaxRetries int
+	// RetryDelay is the base delay between attempts. Default: 800ms.
+	RetryDelay time.Duration
+	// MaxRetryDelay caps the computed backoff delay. Default: 60s.
+	MaxRetryDelay time.Duration
+	// StatusCodes lists additional status codes that should be retried,
+	// beyond the default set (429, 500, 502, 503, 504).
+	StatusCodes []int
+}
+
+var defaultRetriableStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:     true,
+	http.StatusInternalServerError: true,
+	http.StatusBadGateway:          true,
+	http.StatusServiceUnavailable:  true,
+	http.StatusGatewayTimeout:      true,
+}
+
+type retryPolicy struct {
+	options  RetryOptions
+	perRetry []PerRetryPolicy
+}
+
+// NewRetryPolicy returns a PerCallPolicy implementing exponential backoff
+// with jitter, honoring Retry-After, and retrying the given per-retry
+// policies (such as logging or telemetry stamping) on each attempt.
+func NewRetryPolicy(options RetryOptions, perRetry ...PerRetryPolicy) PerCallPolicy {
+	if options.MaxRetries <= 0 {
+		options.MaxRetries = 3
+	}
+	if options.RetryDelay <= 0 {
+		options.RetryDelay = 800 * time.Millisecond
+	}
+	if options.MaxRetryDelay <= 0 {
+		options.MaxRetryDelay = 60 * time.Second
+	}
+	return retryPolicy{options: options, perRetry: perRetry}
+}
+
+func (p retryPolicy) retriable(statusCode int) bool {
+	if defaultRetriableStatusCodes[statusCode] {
+		return true
+	}
+	for _, sc := range p.options.StatusCodes {
+		if sc == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+func (p retryPolicy) Do(req *Request) (*Response, error) {
+	inner := &Request{Raw: req.Raw, policies: append(append([]Policy{}, p.perRetry...), req.policies[req.cur:]...)}
+
+	var lastResp *Response
+	var lastErr error
+	for attempt := 0; attempt <= p.options.MaxRetries; attempt++ {
+		attemptReq := inner.Clone(req.Raw.Context())
+		resp, err := attemptReq.Next()
+		lastResp, lastErr = resp, err
+		if err == nil && !p.retriable(resp.Raw.StatusCode) {
+			return resp, nil
+		}
+		if attempt == p.options.MaxRetries {
+			break
+		}
+		delay := p.backoff(attempt, resp)
+		select {
+		case <-req.Raw.Context().Done():
+			return nil, req.Raw.Context().Err()
+		case <-time.After(delay):
+		}
+	}
+	return lastResp, lastErr
+}
+
+func (p retryPolicy) backoff(attempt int, resp *Response) time.Duration {
+	if resp != nil && resp.Raw != nil {
+		if ra := resp.Raw.Header.Get("Retry-After"); ra != "" {
+			if secs, err := strconv.Atoi(ra); err == nil {
+				return time.Duration(secs) * time.Second
+			}
+		}
+	}
+	delay := p.options.RetryDelay * time.Duration(1<<uint(attempt))
+	if delay > p.options.MaxRetryDelay {
+		delay = p.options.MaxRetryDelay
+	}
+	// Full jitter, as recommended by the AWS architecture blog's backoff post.
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// NewTelemetryPolicy stamps every outgoing request with an X-Request-Id
+// header (for correlation) and a User-Agent describing the calling module.
+func NewTelemetryPolicy(userAgent string) PerCallPolicy {
+	return PolicyFunc(func(req *Request) (*Response, error) {
+		if req.Raw.Header.Get("X-Request-Id") == "" {
+			req.Raw.Header.Set("X-Request-Id", newRequestID())
+		}
+		if userAgent != "" {
+			req.Raw.Header.Set("User-Agent", userAgent)
+		}
+		return req.Next()
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Logger is the subset of logging behavior the logging policy depends on.
+type Logger interface {
+	Printf(format string, args ...any)
+}
+
+// NewLogPolicy logs the method, URL, and outcome of every request.
+func NewLogPolicy(logger Logger) PerCallPolicy {
+	return PolicyFunc(func(req *Request) (*Response, error) {
+		start := time.Now()
+		resp, err := req.Next()
+		if err != nil {
+			logger.Printf("%s %s failed after %s: %v", req.Raw.Method, req.Raw.URL, time.Since(start), err)
+			return resp, err
+		}
+		logger.Printf("%s %s -> %d (%s)", req.Raw.Method, req.Raw.URL, resp.Raw.StatusCode, time.Since(start))
+		return resp, nil
+	})
+}
+
+// NewBearerTokenPolicy returns a policy that attaches an "Authorization:
+// Bearer <token>" header using tokens obtained from cred for the given
+// scopes, caching and refreshing as needed.
+func NewBearerTokenPolicy(cred TokenCredential, scopes []string) PerCallPolicy {
+	tc := &tokenCache{cred: cred, scopes: scopes}
+	return PolicyFunc(func(req *Request) (*Response, error) {
+		token, err := tc.GetToken(req.Raw.Context())
+		if err != nil {
+			return nil, fmt.Errorf("runtime: failed to get token: %w", err)
+		}
+		req.Raw.Header.Set("Authorization", "Bearer "+token)
+		return req.Next()
+	})
+}
+
+// tokenCache caches the last token obtained from a TokenCredential in
+// memory and refreshes it proactively once it is 80% of the way through
+// its lifetime, so a request in flight is never handed an about-to-expire
+// token.
+type tokenCache struct {
+	cred   TokenCredential
+	scopes []string
+
+	mu        sync.Mutex
+	token     AccessToken
+	refreshAt time.Time
+}
+
+func (tc *tokenCache) GetToken(ctx context.Context) (string, error) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if tc.token.Token != "" && time.Now().Before(tc.refreshAt) {
+		return tc.token.Token, nil
+	}
+	token, err := tc.cred.GetToken(ctx, TokenRequestOptions{Scopes: tc.scopes})
+	if err != nil {
+		if tc.token.Token != "" && time.Now().Before(tc.token.ExpiresOn) {
+			// Refresh failed but the cached token is still technically
+			// valid; keep using it rather than failing the request.
+			return tc.token.Token, nil
+		}
+		return "", err
+	}
+	now := time.Now()
+	tc.token = token
+	// Proactively refresh once we're 80% of the way through the token's
+	// lifetime so an in-flight request never gets handed a stale token.
+	tc.refreshAt = now.Add(token.ExpiresOn.Sub(now) * 4 / 5)
+	return tc.token.Token, nil
+}
@@ -0,0 +1,188 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// pollingStrategy identifies how a Poller determines whether the
+// long-running operation is done.
+type pollingStrategy int
+
+const (
+	strategyBodyStatus pollingStrategy = iota
+	strategyAsyncOperation
+	strategyLocation
+)
+
+// PollUntilDoneOptions configures PollUntilDone.
+type PollUntilDoneOptions struct {
+	// Frequency is how often to poll when the response carries no
+	// Retry-After. Default: 5s.
+	Frequency time.Duration
+}
+
+// pollerState is the serializable state needed to resume a Poller in
+// another process.
+type pollerState struct {
+	Strategy pollingStrategy `json:"strategy"`
+	PollURL  string          `json:"pollUrl"`
+	Method   string          `json:"method"`
+	OrigURL  string          `json:"origUrl"`
+	Done     bool            `json:"done"`
+}
+
+// Poller tracks a long-running operation (LRO) and knows how to poll it to
+// completion using one of the three standard REST polling strategies:
+// body-status polling, the Azure-AsyncOperation header, or the Location
+// header.
+type Poller[T any] struct {
+	do    func(ctx context.Context, req *http.Request) (*http.Response, error)
+	state pollerState
+	resp  *http.Response
+	done  bool
+}
+
+// NewPoller constructs a Poller from the initial response to a
+// Create/Update/Delete call, auto-detecting the polling strategy from the
+// response's status code and headers.
+func NewPoller[T any](initialResp *http.Response, do func(ctx context.Context, req *http.Request) (*http.Response, error)) (*Poller[T], error) {
+	p := &Poller[T]{do: do, resp: initialResp}
+	switch {
+	case initialResp.Header.Get("Azure-AsyncOperation") != "":
+		p.state = pollerState{Strategy: strategyAsyncOperation, PollURL: initialResp.Header.Get("Azure-AsyncOperation"), Method: http.MethodGet, OrigURL: initialResp.Request.URL.String()}
+	case initialResp.Header.Get("Operation-Location") != "":
+		p.state = pollerState{Strategy: strategyAsyncOperation, PollURL: initialResp.Header.Get("Operation-Location"), Method: http.MethodGet, OrigURL: initialResp.Request.URL.String()}
+	case initialResp.StatusCode == http.StatusAccepted && initialResp.Header.Get("Location") != "":
+		p.state = pollerState{Strategy: strategyLocation, PollURL: initialResp.Header.Get("Location"), Method: http.MethodGet, OrigURL: initialResp.Request.URL.String()}
+	default:
+		p.state = pollerState{Strategy: strategyBodyStatus, PollURL: initialResp.Request.URL.String(), Method: http.MethodGet, OrigURL: initialResp.Request.URL.String()}
+	}
+	p.done = terminalStatusCode(initialResp.StatusCode) && p.state.Strategy == strategyBodyStatus
+	p.state.Done = p.done
+	// Result reads p.resp.Body once the operation is done, so only close the
+	// initial response here if it isn't already terminal; Poll takes over
+	// closing intermediate responses from here on, and the final one is
+	// closed by Result.
+	if !p.done {
+		initialResp.Body.Close()
+	}
+	return p, nil
+}
+
+// NewPollerFromResumeToken resumes a Poller that was previously serialized
+// with ResumeToken, typically in a different process.
+func NewPollerFromResumeToken[T any](token string, do func(ctx context.Context, req *http.Request) (*http.Response, error)) (*Poller[T], error) {
+	var state pollerState
+	if err := json.Unmarshal([]byte(token), &state); err != nil {
+		return nil, fmt.Errorf("runtime: invalid resume token: %w", err)
+	}
+	return &Poller[T]{do: do, state: state, done: state.Done}, nil
+}
+
+// ResumeToken serializes the poller's current state so it can be handed to
+// NewPollerFromResumeToken in another process.
+func (p *Poller[T]) ResumeToken() (string, error) {
+	b, err := json.Marshal(p.state)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Done reports whether the operation has reached a terminal state.
+func (p *Poller[T]) Done() bool {
+	return p.done
+}
+
+// Poll makes a single polling request and updates the poller's state.
+func (p *Poller[T]) Poll(ctx context.Context) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, p.state.Method, p.state.PollURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	p.resp = resp
+
+	switch p.state.Strategy {
+	case strategyAsyncOperation:
+		var body struct {
+			Status string `json:"status"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		p.done = body.Status == "Succeeded" || body.Status == "Failed" || body.Status == "Canceled"
+	case strategyLocation:
+		p.done = resp.StatusCode != http.StatusAccepted
+		if loc := resp.Header.Get("Location"); loc != "" {
+			p.state.PollURL = loc
+		}
+	default:
+		p.done = terminalStatusCode(resp.StatusCode)
+	}
+	p.state.Done = p.done
+	// Result reads p.resp.Body once the operation is done, so only close
+	// intermediate responses here; the final one is closed by Result.
+	if !p.done {
+		resp.Body.Close()
+	}
+	return resp, nil
+}
+
+// Result decodes the final response body into out. It must only be called
+// once Done reports true.
+func (p *Poller[T]) Result(ctx context.Context, out *T) error {
+	if !p.done {
+		return fmt.Errorf("runtime: poller has not reached a terminal state")
+	}
+	if p.resp == nil || p.resp.Body == nil {
+		return fmt.Errorf("runtime: no response body to decode")
+	}
+	defer p.resp.Body.Close()
+	return json.NewDecoder(p.resp.Body).Decode(out)
+}
+
+// PollUntilDone polls repeatedly, honoring Retry-After, until the operation
+// reaches a terminal state, then decodes the result.
+func (p *Poller[T]) PollUntilDone(ctx context.Context, options *PollUntilDoneOptions) (T, error) {
+	var zero T
+	freq := 5 * time.Second
+	if options != nil && options.Frequency > 0 {
+		freq = options.Frequency
+	}
+	for !p.done {
+		resp, err := p.Poll(ctx)
+		if err != nil {
+			return zero, err
+		}
+		if p.done {
+			break
+		}
+		delay := freq
+		if ra := resp.Header.Get("Retry-After"); ra != "" {
+			if secs, err := strconv.Atoi(ra); err == nil {
+				delay = time.Duration(secs) * time.Second
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+	var out T
+	if err := p.Result(ctx, &out); err != nil {
+		return zero, err
+	}
+	return out, nil
+}
+
+func terminalStatusCode(code int) bool {
+	return code == http.StatusOK || code == http.StatusCreated || code == http.StatusNoContent
+}
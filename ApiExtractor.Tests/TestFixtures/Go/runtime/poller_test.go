@@ -0,0 +1,134 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// newTestResponse builds a minimal *http.Response suitable for driving a
+// Poller in tests: a Request with a URL (NewPoller reads
+// initialResp.Request.URL), a status code, headers, and a body.
+func newTestResponse(statusCode int, header http.Header, body string) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	u, _ := url.Parse("https://example.com/resources/widget")
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewBufferString(body)),
+		Request:    &http.Request{URL: u},
+	}
+}
+
+// TestResumeTokenRoundTrip checks that a Poller's ResumeToken, fed back
+// through NewPollerFromResumeToken, reproduces the same polling state
+// (strategy, URL, done-ness) in what stands in for a different process.
+func TestResumeTokenRoundTrip(t *testing.T) {
+	cases := []struct {
+		name   string
+		header http.Header
+		status int
+	}{
+		{"asyncOperation", http.Header{"Azure-Asyncoperation": []string{"https://example.com/ops/1"}}, http.StatusCreated},
+		{"location", http.Header{"Location": []string{"https://example.com/ops/2"}}, http.StatusAccepted},
+		{"bodyStatus", nil, http.StatusAccepted},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			initial := newTestResponse(tc.status, tc.header, "")
+			poller, err := NewPoller[int](initial, func(ctx context.Context, req *http.Request) (*http.Response, error) {
+				t.Fatal("do should not be called before resuming")
+				return nil, nil
+			})
+			if err != nil {
+				t.Fatalf("NewPoller: %v", err)
+			}
+
+			token, err := poller.ResumeToken()
+			if err != nil {
+				t.Fatalf("ResumeToken: %v", err)
+			}
+
+			resumed, err := NewPollerFromResumeToken[int](token, func(ctx context.Context, req *http.Request) (*http.Response, error) {
+				t.Fatal("do should not be called in this test")
+				return nil, nil
+			})
+			if err != nil {
+				t.Fatalf("NewPollerFromResumeToken: %v", err)
+			}
+
+			if resumed.Done() != poller.Done() {
+				t.Errorf("resumed Done() = %v, want %v", resumed.Done(), poller.Done())
+			}
+			if resumed.state != poller.state {
+				t.Errorf("resumed state = %+v, want %+v", resumed.state, poller.state)
+			}
+		})
+	}
+}
+
+// TestPollUntilDoneBodyStatus drives a Poller through the default
+// body-status strategy across two non-terminal polls and a final terminal
+// one, then checks Result decodes the last response.
+func TestPollUntilDoneBodyStatus(t *testing.T) {
+	responses := []*http.Response{
+		newTestResponse(http.StatusAccepted, nil, ""),
+		newTestResponse(http.StatusAccepted, nil, ""),
+		newTestResponse(http.StatusOK, nil, `{"value":7}`),
+	}
+	var calls int
+	do := func(ctx context.Context, req *http.Request) (*http.Response, error) {
+		resp := responses[calls]
+		calls++
+		return resp, nil
+	}
+
+	initial := newTestResponse(http.StatusAccepted, nil, "")
+	poller, err := NewPoller[struct {
+		Value int `json:"value"`
+	}](initial, do)
+	if err != nil {
+		t.Fatalf("NewPoller: %v", err)
+	}
+	if poller.Done() {
+		t.Fatal("Done() returned true for a 202 Accepted initial response")
+	}
+
+	out, err := poller.PollUntilDone(context.Background(), &PollUntilDoneOptions{Frequency: time.Millisecond})
+	if err != nil {
+		t.Fatalf("PollUntilDone: %v", err)
+	}
+	if out.Value != 7 {
+		t.Errorf("Result.Value = %d, want 7", out.Value)
+	}
+	if calls != len(responses) {
+		t.Errorf("do called %d times, want %d", calls, len(responses))
+	}
+	if !poller.Done() {
+		t.Error("Done() returned false after PollUntilDone succeeded")
+	}
+}
+
+// TestNewPollerTerminalInitialResponse checks that a 201 Created with no
+// LRO headers is recognized as already-done from the initial response,
+// without ever calling do.
+func TestNewPollerTerminalInitialResponse(t *testing.T) {
+	initial := newTestResponse(http.StatusCreated, nil, `{}`)
+	poller, err := NewPoller[struct{}](initial, func(ctx context.Context, req *http.Request) (*http.Response, error) {
+		t.Fatal("do should not be called for an already-terminal initial response")
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("NewPoller: %v", err)
+	}
+	if !poller.Done() {
+		t.Fatal("Done() returned false for a 201 Created initial response")
+	}
+}
@@ -5,7 +5,13 @@ package sample
 
 import (
 	"context"
+	"net/http"
+	goruntime "runtime"
+	"strconv"
+	"sync"
 	"time"
+
+	"sample/runtime"
 )
 
 // ResultStatus represents the status of an operation.
@@ -40,6 +46,17 @@ type ResourceCreateOptions struct {
 	Tags map[string]string
 }
 
+// ListOptions controls how ListResources pages through results.
+type ListOptions struct {
+	// PageSize limits how many resources the service returns per page.
+	// Zero means the service default.
+	PageSize int
+	// ContinuationToken resumes a listing from a previous page.
+	ContinuationToken string
+	// Filter is an optional OData filter expression.
+	Filter string
+}
+
 // SampleClientOptions contains options for configuring SampleClient.
 type SampleClientOptions struct {
 	// RetryCount is the number of retries. Default: 3.
@@ -48,19 +65,46 @@ type SampleClientOptions struct {
 	Timeout time.Duration
 	// APIVersion is the API version. Default: "2024-01-01".
 	APIVersion string
+	// Transport overrides the underlying http.RoundTripper. Default: http.DefaultTransport.
+	Transport http.RoundTripper
+	// PerCallPolicies are added to the pipeline after the built-in telemetry
+	// and authentication policies, ahead of the retry policy, and run once
+	// per logical call.
+	PerCallPolicies []runtime.PerCallPolicy
+	// PerRetryPolicies are appended to the pipeline and run on every retry
+	// attempt, including the first.
+	PerRetryPolicies []runtime.PerRetryPolicy
+	// Credential authenticates requests with a bearer token. If nil, no
+	// Authorization header is sent.
+	Credential TokenCredential
 }
 
+// sampleScopes are the OAuth2 scopes requested for the bearer token used to
+// authenticate against the sample service.
+var sampleScopes = []string{"https://sample.example.com/.default"}
+
 // SampleClient is a client for interacting with the sample service.
 // It provides methods for CRUD operations on resources.
 type SampleClient struct {
 	endpoint string
 	options  SampleClientOptions
+	pipeline *runtime.Pipeline
 }
 
 // NewSampleClient creates a new SampleClient instance.
 // endpoint is the service endpoint URL.
 // options are optional client configuration settings.
 func NewSampleClient(endpoint string, options *SampleClientOptions) (*SampleClient, error) {
+	return NewSampleClientWithCredential(endpoint, nil, options)
+}
+
+// NewSampleClientWithCredential creates a new SampleClient instance that
+// authenticates every request with cred. Pass a nil cred to get the
+// unauthenticated behavior of NewSampleClient.
+// endpoint is the service endpoint URL.
+// cred provides bearer tokens for authentication; may be nil.
+// options are optional client configuration settings.
+func NewSampleClientWithCredential(endpoint string, cred TokenCredential, options *SampleClientOptions) (*SampleClient, error) {
 	opts := SampleClientOptions{
 		RetryCount: 3,
 		Timeout:    30 * time.Second,
@@ -76,10 +120,28 @@ func NewSampleClient(endpoint string, options *SampleClientOptions) (*SampleClie
 		if options.APIVersion != "" {
 			opts.APIVersion = options.APIVersion
 		}
+		opts.Transport = options.Transport
+		opts.PerCallPolicies = options.PerCallPolicies
+		opts.PerRetryPolicies = options.PerRetryPolicies
+		opts.Credential = options.Credential
+	}
+	if cred != nil {
+		opts.Credential = cred
+	}
+
+	perCall := []runtime.PerCallPolicy{
+		runtime.NewTelemetryPolicy("sample-sdk-go"),
+	}
+	if opts.Credential != nil {
+		perCall = append(perCall, runtime.NewBearerTokenPolicy(opts.Credential, sampleScopes))
 	}
+	perCall = append(perCall, opts.PerCallPolicies...)
+	perCall = append(perCall, runtime.NewRetryPolicy(runtime.RetryOptions{MaxRetries: opts.RetryCount}))
+
 	return &SampleClient{
 		endpoint: endpoint,
 		options:  opts,
+		pipeline: runtime.NewPipeline(opts.Transport, perCall, opts.PerRetryPolicies),
 	}, nil
 }
 
@@ -88,11 +150,25 @@ func (c *SampleClient) Endpoint() string {
 	return c.endpoint
 }
 
+// newRequest builds an *http.Request against the client's endpoint. It is a
+// placeholder for the real request construction a generated client would do
+// per-operation (path templating, query params, body serialization).
+func (c *SampleClient) newRequest(ctx context.Context, method, path string) (*http.Request, error) {
+	return http.NewRequestWithContext(ctx, method, c.endpoint+path, nil)
+}
+
 // GetResource retrieves a resource by ID.
 // ctx is the context for cancellation.
 // resourceID is the resource identifier.
 // Returns the resource or an error.
 func (c *SampleClient) GetResource(ctx context.Context, resourceID string) (*Resource, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/resources/"+resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.pipeline.Do(ctx, req); err != nil {
+		return nil, err
+	}
 	return &Resource{
 		ID:        resourceID,
 		Name:      "Test",
@@ -100,43 +176,152 @@ func (c *SampleClient) GetResource(ctx context.Context, resourceID string) (*Res
 	}, nil
 }
 
-// ListResources returns all resources.
+// ListResources returns a Pager that lazily fetches pages of resources
+// matching options.
+func (c *SampleClient) ListResources(options *ListOptions) *runtime.Pager[*Resource] {
+	if options == nil {
+		options = &ListOptions{}
+	}
+	continuationToken := options.ContinuationToken
+	return runtime.NewPager(func(ctx context.Context, token *string) (runtime.PagerResponse[*Resource], error) {
+		path := "/resources"
+		sep := "?"
+		if options.Filter != "" {
+			path += sep + "$filter=" + options.Filter
+			sep = "&"
+		}
+		if options.PageSize > 0 {
+			path += sep + "maxpagesize=" + strconv.Itoa(options.PageSize)
+		}
+		pageToken := continuationToken
+		if token != nil {
+			pageToken = *token
+		}
+		req, err := c.newRequest(ctx, http.MethodGet, path)
+		if err != nil {
+			return runtime.PagerResponse[*Resource]{}, err
+		}
+		if pageToken != "" {
+			req.Header.Set("X-Continuation-Token", pageToken)
+		}
+		if _, err := c.pipeline.Do(ctx, req); err != nil {
+			return runtime.PagerResponse[*Resource]{}, err
+		}
+		return runtime.PagerResponse[*Resource]{Values: []*Resource{}}, nil
+	})
+}
+
+// pollerDo adapts the client's pipeline to the func shape runtime.Poller
+// needs to issue its own polling requests.
+func (c *SampleClient) pollerDo(ctx context.Context, req *http.Request) (*http.Response, error) {
+	resp, err := c.pipeline.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Raw, nil
+}
+
+// BeginCreateResource starts creating a new resource and returns a Poller
+// that tracks the long-running operation to completion.
 // ctx is the context for cancellation.
-// filter is an optional OData filter expression.
-// Returns a slice of resources or an error.
-func (c *SampleClient) ListResources(ctx context.Context, filter string) ([]*Resource, error) {
-	return []*Resource{}, nil
+// options contains the resource creation parameters.
+func (c *SampleClient) BeginCreateResource(ctx context.Context, options ResourceCreateOptions) (*runtime.Poller[Resource], error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/resources")
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.pollerDo(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewPoller[Resource](resp, c.pollerDo)
 }
 
-// CreateResource creates a new resource.
+// CreateResource creates a new resource and waits for the operation to
+// complete. It is equivalent to calling BeginCreateResource followed by
+// PollUntilDone, kept for source compatibility with callers written before
+// CreateResource became a long-running operation.
 // ctx is the context for cancellation.
 // options contains the resource creation parameters.
 // Returns the created resource or an error.
 func (c *SampleClient) CreateResource(ctx context.Context, options ResourceCreateOptions) (*Resource, error) {
-	return &Resource{
-		ID:        "new",
-		Name:      options.Name,
-		Tags:      options.Tags,
-		CreatedAt: time.Now(),
-	}, nil
+	poller, err := c.BeginCreateResource(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	resource, err := poller.PollUntilDone(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	resource.Name = options.Name
+	resource.Tags = options.Tags
+	return &resource, nil
 }
 
-// DeleteResource deletes a resource.
+// BeginDeleteResource starts deleting a resource and returns a Poller that
+// tracks the long-running operation to completion.
+// ctx is the context for cancellation.
+// resourceID is the resource to delete.
+func (c *SampleClient) BeginDeleteResource(ctx context.Context, resourceID string) (*runtime.Poller[Resource], error) {
+	req, err := c.newRequest(ctx, http.MethodDelete, "/resources/"+resourceID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.pollerDo(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewPoller[Resource](resp, c.pollerDo)
+}
+
+// DeleteResource deletes a resource and waits for the operation to
+// complete. Kept for source compatibility; see BeginDeleteResource.
 // ctx is the context for cancellation.
 // resourceID is the resource to delete.
 // Returns an error if deletion fails.
 func (c *SampleClient) DeleteResource(ctx context.Context, resourceID string) error {
-	return nil
+	poller, err := c.BeginDeleteResource(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+	_, err = poller.PollUntilDone(ctx, nil)
+	return err
 }
 
-// UpdateResource updates an existing resource.
+// BeginUpdateResource starts updating an existing resource and returns a
+// Poller that tracks the long-running operation to completion.
+// ctx is the context for cancellation.
+// resourceID is the resource ID.
+// resource contains the updated resource data.
+func (c *SampleClient) BeginUpdateResource(ctx context.Context, resourceID string, resource Resource) (*runtime.Poller[Resource], error) {
+	req, err := c.newRequest(ctx, http.MethodPut, "/resources/"+resourceID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.pollerDo(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.NewPoller[Resource](resp, c.pollerDo)
+}
+
+// UpdateResource updates an existing resource and waits for the operation
+// to complete. Kept for source compatibility; see BeginUpdateResource.
 // ctx is the context for cancellation.
 // resourceID is the resource ID.
 // resource contains the updated resource data.
 // Returns the updated resource or an error.
 func (c *SampleClient) UpdateResource(ctx context.Context, resourceID string, resource Resource) (*Resource, error) {
-	resource.ID = resourceID
-	return &resource, nil
+	poller, err := c.BeginUpdateResource(ctx, resourceID, resource)
+	if err != nil {
+		return nil, err
+	}
+	result, err := poller.PollUntilDone(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	result.ID = resourceID
+	return &result, nil
 }
 
 // Close releases any resources held by the client.
@@ -181,19 +366,114 @@ func CreateDefaultClient(endpoint string) (*SampleClient, error) {
 	return NewSampleClient(endpoint, nil)
 }
 
-// BatchGetResources retrieves multiple resources.
+// BatchOptions controls the concurrency and failure handling of
+// BatchGetResources and BatchDeleteResources.
+type BatchOptions struct {
+	// Concurrency is the number of requests to have in flight at once.
+	// Default: runtime.NumCPU().
+	Concurrency int
+	// ContinueOnError keeps processing the remaining IDs after one fails
+	// instead of aborting the batch early.
+	ContinueOnError bool
+	// PerItemTimeout bounds how long a single item's request may take.
+	// Zero means no per-item timeout.
+	PerItemTimeout time.Duration
+}
+
+// BatchGetResources retrieves multiple resources concurrently, bounded by
+// options.Concurrency, preserving the input order in the returned slice.
+// Each element reports its own success or failure via Result, so a failure
+// on one ID does not discard the resources that succeeded.
 // ctx is the context for cancellation.
 // client is the client to use.
 // ids is a slice of resource IDs.
-// Returns a slice of resources or an error.
-func BatchGetResources(ctx context.Context, client *SampleClient, ids []string) ([]*Resource, error) {
-	results := make([]*Resource, 0, len(ids))
-	for _, id := range ids {
-		r, err := client.GetResource(ctx, id)
-		if err != nil {
-			return nil, err
+// options controls concurrency and failure handling; nil selects defaults.
+func BatchGetResources(ctx context.Context, client *SampleClient, ids []string, options *BatchOptions) ([]Result[*Resource], error) {
+	return runBatch(ctx, ids, options, func(ctx context.Context, id string) (*Resource, error) {
+		return client.GetResource(ctx, id)
+	})
+}
+
+// BatchDeleteResources deletes multiple resources concurrently, with the
+// same concurrency, ordering, and partial-failure semantics as
+// BatchGetResources.
+// ctx is the context for cancellation.
+// client is the client to use.
+// ids is a slice of resource IDs.
+// options controls concurrency and failure handling; nil selects defaults.
+func BatchDeleteResources(ctx context.Context, client *SampleClient, ids []string, options *BatchOptions) ([]Result[*Resource], error) {
+	return runBatch(ctx, ids, options, func(ctx context.Context, id string) (*Resource, error) {
+		return nil, client.DeleteResource(ctx, id)
+	})
+}
+
+// runBatch dispatches op(ctx, id) for every id in ids over a worker pool
+// sized by options.Concurrency, preserving input order in the result and
+// stopping early on the first error unless options.ContinueOnError is set.
+func runBatch(ctx context.Context, ids []string, options *BatchOptions, op func(context.Context, string) (*Resource, error)) ([]Result[*Resource], error) {
+	opts := BatchOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = goruntime.NumCPU()
+	}
+
+	results := make([]Result[*Resource], len(ids))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var firstErr error
+	var mu sync.Mutex
+	sem := make(chan struct{}, opts.Concurrency)
+
+	for i, id := range ids {
+		sem <- struct{}{}
+		if err := ctx.Err(); err != nil {
+			// The batch is already cancelled: record the item as failed
+			// instead of starting it.
+			<-sem
+			results[i] = NewFailureResult[*Resource](err.Error())
+			mu.Lock()
+			if firstErr == nil {
+				firstErr = err
+			}
+			mu.Unlock()
+			continue
 		}
-		results = append(results, r)
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			itemCtx := ctx
+			var itemCancel context.CancelFunc
+			if opts.PerItemTimeout > 0 {
+				itemCtx, itemCancel = context.WithTimeout(ctx, opts.PerItemTimeout)
+				defer itemCancel()
+			}
+
+			resource, err := op(itemCtx, id)
+			if err != nil {
+				results[i] = NewFailureResult[*Resource](err.Error())
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					if !opts.ContinueOnError {
+						cancel()
+					}
+				}
+				mu.Unlock()
+				return
+			}
+			results[i] = NewSuccessResult(resource)
+		}(i, id)
+	}
+	wg.Wait()
+
+	if firstErr != nil && !opts.ContinueOnError {
+		return results, firstErr
 	}
 	return results, nil
 }
@@ -3,18 +3,28 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"go/ast"
 	"go/doc"
+	"go/format"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"unicode"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
 )
 
 // =============================================================================
@@ -52,6 +62,22 @@ type StructApi struct {
 	Methods        []FuncApi  `json:"methods,omitempty"`
 	EntryPoint     bool       `json:"entryPoint,omitempty"`
 	ReExportedFrom string     `json:"reExportedFrom,omitempty"`
+	// Implements lists every interface in this extraction that the struct
+	// satisfies per types.Implements (computed on a *types.Pointer
+	// receiver), and whether that satisfaction goes through a promoted
+	// embedded method. Only populated when the source tree could be loaded
+	// and type-checked; see annotateImplements.
+	Implements []ImplementsInfo `json:"implements,omitempty"`
+}
+
+// ImplementsInfo records that a StructApi satisfies Interface, and whether
+// it does so through a promoted embedded method.
+type ImplementsInfo struct {
+	Interface string `json:"interface"`
+	// Promoted is true when at least one of Interface's methods is reached
+	// through an embedded field's promoted method set rather than a method
+	// declared directly on the struct.
+	Promoted bool `json:"promoted,omitempty"`
 }
 
 type IfaceApi struct {
@@ -162,11 +188,12 @@ func isStdlibPackage(pkgPath string) bool {
 
 func main() {
 	var outputJson, outputStub, pretty bool
-	var usageApiFile string
+	var usageApiFile, suggestApiFile string
 	flag.BoolVar(&outputJson, "json", false, "Output JSON")
 	flag.BoolVar(&outputStub, "stub", false, "Output Go stubs")
 	flag.BoolVar(&pretty, "pretty", false, "Pretty print JSON")
 	flag.StringVar(&usageApiFile, "usage", "", "Analyze samples usage: -usage <api_json_file> <samples_path>")
+	flag.StringVar(&suggestApiFile, "suggest", "", "Suggest stubs for uncovered methods: -suggest <api_json_file> <samples_path>")
 	flag.Parse()
 
 	// Handle --usage mode
@@ -179,9 +206,20 @@ func main() {
 		return
 	}
 
+	// Handle --suggest mode
+	if suggestApiFile != "" {
+		if flag.NArg() < 1 {
+			fmt.Fprintln(os.Stderr, "Usage: go run extract_api.go -suggest <api_json_file> <samples_path>")
+			os.Exit(1)
+		}
+		suggestUncovered(suggestApiFile, flag.Arg(0), flag.Arg(0))
+		return
+	}
+
 	if flag.NArg() < 1 {
 		fmt.Fprintln(os.Stderr, "Usage: go run extract_api.go <path> [--json] [--stub] [--pretty]")
 		fmt.Fprintln(os.Stderr, "       go run extract_api.go -usage <api_json_file> <samples_path>")
+		fmt.Fprintln(os.Stderr, "       go run extract_api.go -suggest <api_json_file> <samples_path>")
 		os.Exit(1)
 	}
 
@@ -230,8 +268,191 @@ type UncoveredOp struct {
 	Sig    string `json:"sig"`
 }
 
+// loadSamplesPackages attempts to load and type-check samplesPath with
+// go/packages. Returns nil when the tree can't be loaded or type-checked
+// (e.g. a bare source snapshot with no go.mod of its own), in which case
+// analyzeUsage falls back to its AST-only, name-matching strategies.
+func loadSamplesPackages(samplesPath string) []*packages.Package {
+	cfg := &packages.Config{
+		Mode: packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax |
+			packages.NeedName | packages.NeedImports | packages.NeedDeps,
+		Dir: samplesPath,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil || len(pkgs) == 0 || packages.PrintErrors(pkgs) > 0 {
+		return nil
+	}
+	return pkgs
+}
+
+// resolveReceiverViaTypes resolves sel's receiver to a known client type
+// name using info's real method-set selection for that call, rather than
+// name overlap: Selections already accounts for embedded-field promotion
+// and pointer vs. value receivers, so a method promoted from an embedded
+// type resolves to the outer (client) type exactly as Go itself would
+// dispatch it.
+func resolveReceiverViaTypes(info *types.Info, sel *ast.SelectorExpr, clientNames map[string]bool) (string, bool) {
+	selection, ok := info.Selections[sel]
+	if !ok {
+		return "", false
+	}
+	recvType := selection.Recv()
+	if ptr, ok := recvType.(*types.Pointer); ok {
+		recvType = ptr.Elem()
+	}
+	named, ok := recvType.(*types.Named)
+	if !ok || !clientNames[named.Obj().Name()] {
+		return "", false
+	}
+	return named.Obj().Name(), true
+}
+
+// typeCheckedCoverage is the precise counterpart to the AST-only walk in
+// analyzeUsage: it resolves every call's receiver through go/types instead
+// of buildVarTypeMap/resolveExprType's string heuristics, so it also picks
+// up receivers passed through function parameters, stored in a map/slice,
+// or reached through an interface value - cases the local variable tracker
+// can't see. Only runs over packages that were actually loaded and
+// type-checked; see loadSamplesPackages.
+func typeCheckedCoverage(pkgs []*packages.Package, clientMethods map[string]map[string]string, clientNames map[string]bool, absPath string) ([]CoveredOp, map[string]bool, map[string]bool) {
+	covered := []CoveredOp{}
+	seenOps := make(map[string]bool)
+	patterns := make(map[string]bool)
+
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		for fi, file := range pkg.Syntax {
+			var filename string
+			if fi < len(pkg.CompiledGoFiles) {
+				filename = pkg.CompiledGoFiles[fi]
+			}
+			relPath, _ := filepath.Rel(absPath, filename)
+
+			ast.Inspect(file, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				sel, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				client, ok := resolveReceiverViaTypes(pkg.TypesInfo, sel, clientNames)
+				if !ok {
+					return true
+				}
+				methodName := sel.Sel.Name
+				methods, ok := clientMethods[client]
+				if !ok {
+					return true
+				}
+				if _, hasMethod := methods[methodName]; !hasMethod {
+					return true
+				}
+				key := client + "." + methodName
+				if !seenOps[key] {
+					seenOps[key] = true
+					pos := pkg.Fset.Position(call.Pos())
+					covered = append(covered, CoveredOp{
+						Client: client,
+						Method: methodName,
+						File:   relPath,
+						Line:   pos.Line,
+					})
+				}
+				return true
+			})
+
+			ast.Inspect(file, func(n ast.Node) bool {
+				switch n.(type) {
+				case *ast.DeferStmt:
+					patterns["defer-cleanup"] = true
+				case *ast.GoStmt:
+					patterns["goroutine"] = true
+				case *ast.SelectStmt:
+					patterns["channel-select"] = true
+				}
+				return true
+			})
+		}
+	}
+	return covered, seenOps, patterns
+}
+
+// ssaReachableCoverage builds an SSA program for pkgs and walks every edge
+// of its CHA call graph, recording a CoveredOp for each edge whose callee is
+// a method on a known client type. Unlike typeCheckedCoverage's direct
+// selector walk, this also catches calls made through helper functions,
+// factory closures, and interface-typed parameters: CHA's call graph is
+// conservative over the whole program, so an interface method call already
+// carries edges to every type that could implement it, client types
+// included. Returns nil if the program can't be built (e.g. pkgs carries
+// type errors) - this pass is a best-effort addition on top of
+// typeCheckedCoverage, never the only source of coverage.
+func ssaReachableCoverage(pkgs []*packages.Package, clientNames map[string]bool, absPath string) (covered []CoveredOp) {
+	defer func() {
+		if recover() != nil {
+			covered = nil
+		}
+	}()
+
+	prog, _ := ssautil.AllPackages(pkgs, ssa.InstantiateGenerics)
+	prog.Build()
+	cg := cha.CallGraph(prog)
+
+	seenOps := make(map[string]bool)
+	for _, node := range cg.Nodes {
+		for _, edge := range node.Out {
+			fn := edge.Callee.Func
+			if fn == nil || edge.Site == nil || fn.Signature.Recv() == nil {
+				continue
+			}
+			recvType := fn.Signature.Recv().Type()
+			if ptr, ok := recvType.(*types.Pointer); ok {
+				recvType = ptr.Elem()
+			}
+			named, ok := recvType.(*types.Named)
+			if !ok || !clientNames[named.Obj().Name()] {
+				continue
+			}
+			client := named.Obj().Name()
+			key := client + "." + fn.Name()
+			if seenOps[key] {
+				continue
+			}
+			seenOps[key] = true
+			pos := prog.Fset.Position(edge.Site.Pos())
+			relPath, _ := filepath.Rel(absPath, pos.Filename)
+			covered = append(covered, CoveredOp{
+				Client: client,
+				Method: fn.Name(),
+				File:   relPath,
+				Line:   pos.Line,
+			})
+		}
+	}
+	return covered
+}
+
 // ===== Usage Analysis =====
+
+// analyzeUsage prints computeUsage's result as the -usage CLI mode's JSON
+// output. See computeUsage for the analysis itself.
 func analyzeUsage(apiJsonFile, samplesPath string) {
+	result, _, _, _, _ := computeUsage(apiJsonFile, samplesPath)
+	output, _ := json.Marshal(result)
+	fmt.Println(string(output))
+}
+
+// computeUsage is the shared core behind both -usage (prints the result as
+// JSON) and -suggest (turns result.Uncovered into stub files): it loads the
+// API index, walks samplesPath for client-method call sites, and returns
+// the coverage result alongside the return-type maps and client name set
+// -suggest needs to plan construction paths for the methods it found no
+// call site for.
+func computeUsage(apiJsonFile, samplesPath string) (UsageResult, *ApiIndex, map[string]string, map[string]string, map[string]bool) {
 	// Load API index
 	apiData, err := os.ReadFile(apiJsonFile)
 	if err != nil {
@@ -273,6 +494,22 @@ func analyzeUsage(apiJsonFile, samplesPath string) {
 	interfaceImplementers := make(map[string][]StructApi)
 	for ifaceName, methods := range interfaceMethods {
 		for _, s := range allStructs {
+			// Prefer annotateImplements' go/types-derived answer over the
+			// name-overlap heuristic below; it's exact (real method-set
+			// membership, not name coincidence). It is empty when the tree
+			// couldn't be type-checked at extraction time, and also for a
+			// struct that satisfies no interface; both go through the
+			// heuristic.
+			if len(s.Implements) > 0 {
+				for _, impl := range s.Implements {
+					if impl.Interface == ifaceName {
+						interfaceImplementers[ifaceName] = append(interfaceImplementers[ifaceName], s)
+						break
+					}
+				}
+				continue
+			}
+
 			structMethods := make(map[string]bool)
 			for _, m := range s.Methods {
 				structMethods[m.Name] = true
@@ -422,9 +659,7 @@ func analyzeUsage(apiJsonFile, samplesPath string) {
 
 	if len(clientMethods) == 0 {
 		result := UsageResult{FileCount: 0, Covered: []CoveredOp{}, Uncovered: []UncoveredOp{}, Patterns: []string{}}
-		output, _ := json.Marshal(result)
-		fmt.Println(string(output))
-		return
+		return result, &apiIndex, map[string]string{}, map[string]string{}, map[string]bool{}
 	}
 
 	// Build set of known client type names for local type inference
@@ -455,6 +690,31 @@ func analyzeUsage(apiJsonFile, samplesPath string) {
 	seenOps := make(map[string]bool)
 	patterns := make(map[string]bool)
 
+	// Prefer the go/types-checked path when the samples tree can be loaded
+	// and type-checked (e.g. it carries its own go.mod): it resolves every
+	// receiver through real method-set membership instead of the
+	// string/name heuristics below, so embedded methods, generic
+	// instantiations, and receivers threaded through params/maps/closures
+	// all resolve correctly. The AST-only strategies below run in either
+	// case, adding only ops not already in seenOps, and are the sole source
+	// of coverage when the tree has no module to load, the common case for
+	// this tool.
+	if typedPkgs := loadSamplesPackages(absPath); len(typedPkgs) > 0 {
+		covered, seenOps, patterns = typeCheckedCoverage(typedPkgs, clientMethods, clientNames, absPath)
+
+		// Add whatever the CHA call graph reaches beyond direct selector
+		// call sites - helper functions, factory closures, interface-typed
+		// parameters - deduping against what typeCheckedCoverage already
+		// found.
+		for _, op := range ssaReachableCoverage(typedPkgs, clientNames, absPath) {
+			key := op.Client + "." + op.Method
+			if !seenOps[key] {
+				seenOps[key] = true
+				covered = append(covered, op)
+			}
+		}
+	}
+
 	fset := token.NewFileSet()
 	for _, file := range goFiles {
 		src, err := os.ReadFile(file)
@@ -631,8 +891,223 @@ func analyzeUsage(apiJsonFile, samplesPath string) {
 		Patterns:  patternList,
 	}
 
-	output, _ := json.Marshal(result)
-	fmt.Println(string(output))
+	return result, &apiIndex, methodReturnTypeMap, functionReturnTypeMap, clientNames
+}
+
+// ===== Suggestion Generation (-suggest mode) =====
+
+// constructionStep is one call in the chain findConstructionPath builds
+// from a root entry-point function to a target client type: IsFunc marks
+// the first step, a package-level constructor call; every step after it
+// is a method call on the previous step's VarName.
+type constructionStep struct {
+	VarName string // the variable this step assigns, e.g. "v0"
+	Type    string // the resulting client type
+	Call    string // the function or method name, e.g. "NewClient" or "GetBlobClient"
+	IsFunc  bool
+}
+
+// maxConstructionDepth bounds findConstructionPath's BFS so a pathological
+// return-type graph (e.g. a cycle of getters) can't run away.
+const maxConstructionDepth = 4
+
+var identBoundary = regexp.MustCompile(`\w`)
+
+// paramsOf returns the substring between sig's outermost matching
+// parentheses, i.e. its parameter list, or "" if sig has none.
+func paramsOf(sig string) string {
+	start := strings.Index(sig, "(")
+	if start < 0 {
+		return ""
+	}
+	depth := 0
+	for i := start; i < len(sig); i++ {
+		switch sig[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return sig[start+1 : i]
+			}
+		}
+	}
+	return sig[start+1:]
+}
+
+// isRootConstructor reports whether sig's parameter list mentions none of
+// clientNames, i.e. it can be called without already holding some other
+// client value — a valid BFS starting point for findConstructionPath.
+func isRootConstructor(sig string, clientNames map[string]bool) bool {
+	params := paramsOf(sig)
+	for name := range clientNames {
+		re := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+		if re.MatchString(params) {
+			return false
+		}
+	}
+	return true
+}
+
+// findConstructionPath does a breadth-first search over the return-type
+// graph (functionReturnTypeMap's functions, then methodReturnTypeMap's
+// methods) for the shortest chain of calls that produces a value of type
+// target, starting only from root entry-point functions (see
+// isRootConstructor). Returns nil if target isn't reachable within
+// maxConstructionDepth hops.
+func findConstructionPath(target string, funcSigs map[string]string, funcRetMap, methodRetMap map[string]string, clientNames map[string]bool) []constructionStep {
+	type queued struct {
+		typ  string
+		path []constructionStep
+	}
+
+	visited := map[string]bool{}
+	var queue []queued
+	for fn, ret := range funcRetMap {
+		if !isRootConstructor(funcSigs[fn], clientNames) || visited[ret] {
+			continue
+		}
+		visited[ret] = true
+		queue = append(queue, queued{ret, []constructionStep{{VarName: "v0", Type: ret, Call: fn, IsFunc: true}}})
+	}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur.typ == target {
+			return cur.path
+		}
+		if len(cur.path) >= maxConstructionDepth {
+			continue
+		}
+		for key, ret := range methodRetMap {
+			owner, method, ok := strings.Cut(key, ".")
+			if !ok || owner != cur.typ || visited[ret] {
+				continue
+			}
+			visited[ret] = true
+			next := make([]constructionStep, len(cur.path), len(cur.path)+1)
+			copy(next, cur.path)
+			next = append(next, constructionStep{
+				VarName: fmt.Sprintf("v%d", len(cur.path)),
+				Type:    ret,
+				Call:    method,
+			})
+			queue = append(queue, queued{ret, next})
+		}
+	}
+	return nil
+}
+
+// renderSuggestionStub synthesizes a sample_uncovered.go source for ops, one
+// func Example_<Client>_<Method> per op that has a construction path from a
+// root entry point (see findConstructionPath). Call arguments are rendered
+// as a "/* TODO: args */" placeholder rather than real values, since the API
+// index only carries each call's rendered signature text, not typed
+// argument data to synthesize values from — so the stub parses and is
+// shaped right, but isn't guaranteed to compile unmodified. Ops with no
+// construction path are skipped and logged to stderr, not silently dropped.
+func renderSuggestionStub(pkgName string, ops []UncoveredOp, funcSigs map[string]string, funcRetMap, methodRetMap map[string]string, clientNames map[string]bool) ([]byte, error) {
+	var body strings.Builder
+	fmt.Fprintf(&body, "package main\n\n")
+	if identBoundary.MatchString(pkgName) {
+		fmt.Fprintf(&body, "import %q\n\n", pkgName)
+	}
+
+	wrote := 0
+	for _, op := range ops {
+		path := findConstructionPath(op.Client, funcSigs, funcRetMap, methodRetMap, clientNames)
+		if path == nil {
+			fmt.Fprintf(os.Stderr, "suggest: no construction path found for %s.%s, skipping\n", op.Client, op.Method)
+			continue
+		}
+		fmt.Fprintf(&body, "func Example_%s_%s() {\n", op.Client, op.Method)
+		for i, step := range path {
+			recv := pkgName + "."
+			if !step.IsFunc {
+				recv = path[i-1].VarName + "."
+			}
+			fmt.Fprintf(&body, "\t%s := %s%s( /* TODO: args */ )\n", step.VarName, recv, step.Call)
+		}
+		fmt.Fprintf(&body, "\t_ = %s.%s( /* TODO: args */ ) // %s\n", path[len(path)-1].VarName, op.Method, op.Sig)
+		fmt.Fprintf(&body, "}\n\n")
+		wrote++
+	}
+	if wrote == 0 {
+		return nil, fmt.Errorf("no reachable uncovered ops to render")
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "sample_uncovered.go", body.String(), parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("generated stub failed to parse: %w", err)
+	}
+	if identBoundary.MatchString(pkgName) {
+		astutil.AddImport(fset, file, pkgName)
+	}
+
+	var out bytes.Buffer
+	if err := format.Node(&out, fset, file); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// suggestUncovered runs the same analysis as analyzeUsage, then writes one
+// stub file per package into outDir (sample_uncovered.go, or
+// sample_uncovered_<pkg>.go when there is more than one package) with an
+// Example_<Client>_<Method> stub for each reachable UncoveredOp, turning the usage report's
+// list of gaps into scaffolding a sample author can fill in. See
+// renderSuggestionStub for what "reachable" means and what's skipped.
+func suggestUncovered(apiJsonFile, samplesPath, outDir string) {
+	result, apiIndex, methodRetMap, funcRetMap, clientNames := computeUsage(apiJsonFile, samplesPath)
+	if len(result.Uncovered) == 0 {
+		fmt.Println("suggest: no uncovered methods, nothing to suggest")
+		return
+	}
+
+	funcSigs := make(map[string]string)
+	clientPkg := make(map[string]string)
+	for _, pkg := range apiIndex.Packages {
+		for _, fn := range pkg.Functions {
+			funcSigs[fn.Name] = fn.Sig
+		}
+		for _, s := range pkg.Structs {
+			clientPkg[s.Name] = pkg.Name
+		}
+		for _, iface := range pkg.Interfaces {
+			clientPkg[iface.Name] = pkg.Name
+		}
+	}
+
+	byPackage := make(map[string][]UncoveredOp)
+	for _, op := range result.Uncovered {
+		byPackage[clientPkg[op.Client]] = append(byPackage[clientPkg[op.Client]], op)
+	}
+
+	for pkgName, ops := range byPackage {
+		sort.Slice(ops, func(i, j int) bool {
+			if ops[i].Client != ops[j].Client {
+				return ops[i].Client < ops[j].Client
+			}
+			return ops[i].Method < ops[j].Method
+		})
+		src, err := renderSuggestionStub(pkgName, ops, funcSigs, funcRetMap, methodRetMap, clientNames)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "suggest: %s: %v\n", pkgName, err)
+			continue
+		}
+		filename := "sample_uncovered.go"
+		if len(byPackage) > 1 && pkgName != "" {
+			filename = "sample_uncovered_" + pkgName + ".go"
+		}
+		outPath := filepath.Join(outDir, filename)
+		if err := os.WriteFile(outPath, src, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "suggest: writing %s: %v\n", outPath, err)
+			continue
+		}
+		fmt.Println("suggest: wrote", outPath)
+	}
 }
 
 func getReferencedTypes(s StructApi, allTypeNames map[string]bool) map[string]bool {
@@ -894,6 +1369,94 @@ func resolveExprType(expr ast.Expr, clientNames map[string]bool, varTypes, metho
 	return ""
 }
 
+// loadTypesIndex is a best-effort go/types view of rootPath, used by
+// annotateImplements to compute real interface satisfaction instead of the
+// method-name-overlap heuristic analyzeUsage otherwise falls back to. Nil
+// when the tree can't be loaded and type-checked, e.g. a bare source
+// snapshot with no go.mod.
+func loadTypesIndex(rootPath string) []*packages.Package {
+	cfg := &packages.Config{
+		Mode: packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax |
+			packages.NeedName | packages.NeedImports | packages.NeedDeps,
+		Dir: rootPath,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil || len(pkgs) == 0 || packages.PrintErrors(pkgs) > 0 {
+		return nil
+	}
+	return pkgs
+}
+
+// annotateImplements overlays types.Implements-based interface satisfaction
+// onto every StructApi in pkgMap. For each (struct, interface) pair that
+// satisfies types.Implements on a pointer receiver, it also records
+// whether satisfaction depends on a promoted embedded method:
+// types.NewMethodSet(types.NewPointer(T)).Lookup returns a *types.Selection
+// whose Index() has length > 1 exactly when the method was reached through
+// an embedded field rather than declared directly on T.
+func annotateImplements(pkgMap map[string]*PackageApi, typesPkgs []*packages.Package) {
+	if len(typesPkgs) == 0 {
+		return
+	}
+
+	structs := make(map[string]*types.Named)
+	ifaces := make(map[string]*types.Named)
+	for _, tp := range typesPkgs {
+		if tp.Types == nil {
+			continue
+		}
+		scope := tp.Types.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			switch named.Underlying().(type) {
+			case *types.Struct:
+				structs[name] = named
+			case *types.Interface:
+				ifaces[name] = named
+			}
+		}
+	}
+	if len(structs) == 0 || len(ifaces) == 0 {
+		return
+	}
+
+	for _, pkgApi := range pkgMap {
+		for si := range pkgApi.Structs {
+			named, ok := structs[pkgApi.Structs[si].Name]
+			if !ok {
+				continue
+			}
+			ptr := types.NewPointer(named)
+			ptrMethodSet := types.NewMethodSet(ptr)
+			for ifaceName, ifaceNamed := range ifaces {
+				iface, ok := ifaceNamed.Underlying().(*types.Interface)
+				if !ok || !types.Implements(ptr, iface) {
+					continue
+				}
+				promoted := false
+				for m := 0; m < iface.NumMethods(); m++ {
+					method := iface.Method(m)
+					if sel := ptrMethodSet.Lookup(method.Pkg(), method.Name()); sel != nil && len(sel.Index()) > 1 {
+						promoted = true
+						break
+					}
+				}
+				pkgApi.Structs[si].Implements = append(pkgApi.Structs[si].Implements, ImplementsInfo{
+					Interface: ifaceName,
+					Promoted:  promoted,
+				})
+			}
+		}
+	}
+}
+
 func extractPackage(rootPath string) (*ApiIndex, error) {
 	absPath, err := filepath.Abs(rootPath)
 	if err != nil {
@@ -997,6 +1560,13 @@ func extractPackage(rootPath string) (*ApiIndex, error) {
 		}
 	}
 
+	// Overlay real interface satisfaction (types.Implements) onto the
+	// structs just extracted, in place of the method-name-overlap
+	// heuristic analyzeUsage otherwise has to fall back to. No-op when the
+	// tree can't be type-checked, e.g. a bare source snapshot with no
+	// go.mod of its own.
+	annotateImplements(packages, loadTypesIndex(absPath))
+
 	// Sort packages by name
 	var sortedPkgs []PackageApi
 	for _, p := range packages {
@@ -0,0 +1,49 @@
+// Package a exercises usagecheck against chained factories, interface
+// implementers, and pointer receivers.
+package a // want "uncovered SDK method: Client.Delete is never called" "uncovered SDK method: SubClient.SubDelete is never called"
+
+// Client is a pointer-receiver SDK client; NewSubClient is a factory method
+// chained directly off a constructor call below.
+type Client struct{} // want Client:"covered:NewSubClient"
+
+func NewClient() *Client { return &Client{} }
+
+func (c *Client) Get() string { return "" }
+
+func (c *Client) Put(v string) {}
+
+// Delete is never called anywhere in this package, and should be reported.
+func (c *Client) Delete() {}
+
+func (c *Client) NewSubClient() *SubClient { return &SubClient{} }
+
+// SubClient is only reachable through Client.NewSubClient's return value.
+type SubClient struct{} // want SubClient:"covered:SubGet"
+
+func (s *SubClient) SubGet() string { return "" }
+
+// SubDelete is never called anywhere in this package, and should be reported.
+func (s *SubClient) SubDelete() {}
+
+// Fetcher is implemented by concreteFetcher; calls through the interface
+// value must still resolve back to Fetcher's method set.
+type Fetcher interface { // want Fetcher:"covered:Fetch"
+	Fetch() string
+}
+
+type concreteFetcher struct{}
+
+func (f *concreteFetcher) Fetch() string { return "" }
+
+func useClient() {
+	c := NewClient()
+	_ = c.Get()
+	c.Put("x")
+
+	// Chained factory call: NewSubClient()'s result has no intermediate
+	// variable before SubGet is called on it.
+	_ = c.NewSubClient().SubGet()
+
+	var f Fetcher = &concreteFetcher{}
+	_ = f.Fetch()
+}
@@ -0,0 +1,156 @@
+// Package usagecheck wraps extract_api.go's usage-coverage logic as a
+// go/analysis Analyzer, so the same "is this SDK method ever called"
+// check can run under singlechecker, multichecker, go vet, golangci-lint,
+// or staticcheck instead of only as the standalone -usage CLI mode.
+package usagecheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"os"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// apiIndexPath is populated by the -usagecheck.api flag.
+var apiIndexPath string
+
+// Analyzer reports a diagnostic for every client method the API index at
+// -usagecheck.api knows about but the analyzed package never calls, and
+// exports a coveredFact on each client type declared in the package that
+// it does find called. A type holds a single coveredFact, so when several
+// of its methods are called the fact names only the last one the walk
+// reached.
+var Analyzer = &analysis.Analyzer{
+	Name:      "usagecheck",
+	Doc:       "reports SDK client methods from an API index that are never called in the analyzed package",
+	Run:       run,
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	FactTypes: []analysis.Fact{new(coveredFact)},
+}
+
+func init() {
+	Analyzer.Flags.StringVar(&apiIndexPath, "api", "", "path to the API index JSON produced by extract_api.go -json")
+}
+
+// coveredFact marks that at least one call site resolved to Method on the
+// type it's attached to.
+type coveredFact struct{ Method string }
+
+func (*coveredFact) AFact() {}
+
+func (f *coveredFact) String() string { return "covered:" + f.Method }
+
+// apiModel mirrors the subset of extract_api.go's ApiIndex this analyzer
+// needs. Duplicated rather than imported, because extract_api.go is a
+// //go:build ignore CLI entry point (package main), not an importable
+// package.
+type apiModel struct {
+	Packages []struct {
+		Structs    []apiTypeModel `json:"structs"`
+		Interfaces []apiTypeModel `json:"interfaces"`
+	} `json:"packages"`
+}
+
+type apiTypeModel struct {
+	Name    string `json:"name"`
+	Methods []struct {
+		Name string `json:"name"`
+	} `json:"methods"`
+}
+
+func loadClientMethods(path string) (map[string]map[string]bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var api apiModel
+	if err := json.Unmarshal(data, &api); err != nil {
+		return nil, err
+	}
+
+	clients := make(map[string]map[string]bool)
+	addType := func(t apiTypeModel) {
+		if len(t.Methods) == 0 {
+			return
+		}
+		methods := make(map[string]bool, len(t.Methods))
+		for _, m := range t.Methods {
+			methods[m.Name] = true
+		}
+		clients[t.Name] = methods
+	}
+	for _, pkg := range api.Packages {
+		for _, s := range pkg.Structs {
+			addType(s)
+		}
+		for _, i := range pkg.Interfaces {
+			addType(i)
+		}
+	}
+	return clients, nil
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	if apiIndexPath == "" {
+		return nil, fmt.Errorf("usagecheck: -usagecheck.api flag is required")
+	}
+	clientMethods, err := loadClientMethods(apiIndexPath)
+	if err != nil {
+		return nil, fmt.Errorf("usagecheck: loading API index: %w", err)
+	}
+
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	seen := make(map[string]bool)
+
+	insp.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return
+		}
+		selection, ok := pass.TypesInfo.Selections[sel]
+		if !ok {
+			return
+		}
+		recvType := selection.Recv()
+		if ptr, ok := recvType.(*types.Pointer); ok {
+			recvType = ptr.Elem()
+		}
+		named, ok := recvType.(*types.Named)
+		if !ok {
+			return
+		}
+		client := named.Obj().Name()
+		methods, ok := clientMethods[client]
+		if !ok || !methods[sel.Sel.Name] {
+			return
+		}
+		key := client + "." + sel.Sel.Name
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		if named.Obj().Pkg() == pass.Pkg {
+			pass.ExportObjectFact(named.Obj(), &coveredFact{Method: sel.Sel.Name})
+		}
+	})
+
+	if len(pass.Files) == 0 {
+		return nil, nil
+	}
+	for client, methods := range clientMethods {
+		for method := range methods {
+			if seen[client+"."+method] {
+				continue
+			}
+			pass.Reportf(pass.Files[0].Package, "uncovered SDK method: %s.%s is never called", client, method)
+		}
+	}
+
+	return nil, nil
+}
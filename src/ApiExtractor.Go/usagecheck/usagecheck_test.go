@@ -0,0 +1,16 @@
+package usagecheck
+
+import (
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	testdata := analysistest.TestData()
+	if err := Analyzer.Flags.Set("api", filepath.Join(testdata, "api.json")); err != nil {
+		t.Fatal(err)
+	}
+	analysistest.Run(t, testdata, Analyzer, "a")
+}
@@ -3,10 +3,15 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"go/ast"
+	"go/build"
 	"go/doc"
+	"go/importer"
 	"go/parser"
 	"go/token"
 	"go/types"
@@ -14,9 +19,16 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	goruntime "runtime"
 	"sort"
 	"strings"
 	"unicode"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
 )
 
 // =============================================================================
@@ -27,6 +39,48 @@ type ApiIndex struct {
 	Package      string           `json:"package"`
 	Packages     []PackageApi     `json:"packages"`
 	Dependencies []DependencyInfo `json:"dependencies,omitempty"`
+	// Contexts lists every build context ("GOOS/GOARCH" or
+	// "GOOS/GOARCH/cgo") this index was extracted under, when extracted via
+	// extractPackageMatrix. Empty for a single-context extraction.
+	Contexts []string `json:"contexts,omitempty"`
+	// Xrefs is a best-effort cross-reference index: for each exported
+	// symbol, the other exported symbols its body refers to. Keys are
+	// SymbolIDs of the form "pkgName.Name" for package-level funcs/types, or
+	// "pkgName.Receiver.Method" for methods; referenced symbols are always
+	// package-level funcs/types. Built from matching bare identifiers
+	// against known exported names, so it only captures direct references
+	// (calls, type assertions, composite literal types); a method call is
+	// not recorded as a reference, and a selector call reached through a
+	// local variable of unresolved type is not tracked. Absent when nothing
+	// was extracted.
+	Xrefs map[SymbolID][]SymbolRef `json:"xrefs,omitempty"`
+	// Instantiations records every generic instantiation ("Result[string,
+	// error]") observed in a field, parameter, or result type, keyed by
+	// that same rendered text so repeats collapse to one entry. Whether an
+	// instantiation's Args still satisfy Generic's declared constraints is
+	// left to a future diff pass; this only records that the instantiation
+	// exists.
+	Instantiations map[string]Instantiation `json:"instantiations,omitempty"`
+}
+
+// Instantiation is one generic instantiation site, e.g. the "Result[string,
+// error]" in a field of type Result[string, error].
+type Instantiation struct {
+	// Generic is the uninstantiated generic type's name, e.g. "Result".
+	Generic string `json:"generic"`
+	// Args are the instantiation's type arguments in source order, e.g.
+	// ["string", "error"].
+	Args []string `json:"args"`
+}
+
+// SymbolID names an exported symbol within a single extraction, in
+// "pkgName.Name" or "pkgName.Receiver.Method" form.
+type SymbolID string
+
+// SymbolRef is one edge in ApiIndex.Xrefs: a reference from the owning
+// SymbolID to Symbol.
+type SymbolRef struct {
+	Symbol SymbolID `json:"symbol"`
 }
 
 type PackageApi struct {
@@ -54,11 +108,30 @@ type StructApi struct {
 	IsDeprecated   bool       `json:"deprecated,omitempty"`
 	DeprecatedMsg  string     `json:"deprecatedMsg,omitempty"`
 	TypeParams     []string   `json:"typeParams,omitempty"`
-	Embeds         []string   `json:"embeds,omitempty"`
-	Fields         []FieldApi `json:"fields,omitempty"`
-	Methods        []FuncApi  `json:"methods,omitempty"`
-	EntryPoint     bool       `json:"entryPoint,omitempty"`
-	ReExportedFrom string     `json:"reExportedFrom,omitempty"`
+	// TypeParamDetails is the structured counterpart to TypeParams, broken
+	// out the way IfaceApi.Constraint breaks out a constraint interface's
+	// type-set union; see TypeParamInfo.
+	TypeParamDetails []TypeParamInfo `json:"typeParamDetails,omitempty"`
+	Embeds           []string        `json:"embeds,omitempty"`
+	Fields           []FieldApi      `json:"fields,omitempty"`
+	Methods          []FuncApi       `json:"methods,omitempty"`
+	EntryPoint       bool            `json:"entryPoint,omitempty"`
+	ReExportedFrom   string          `json:"reExportedFrom,omitempty"`
+	// Contexts lists the build contexts (as "GOOS/GOARCH" or
+	// "GOOS/GOARCH/cgo") this symbol was observed under, when extracted via
+	// a build-context matrix. Empty means the symbol is portable across the
+	// whole requested matrix.
+	Contexts []string `json:"contexts,omitempty"`
+	// Implements lists every interface this struct satisfies on a pointer
+	// receiver, per types.Implements: module-local interfaces by bare name
+	// and stdlib ones from stdlibInterfaces qualified as "io.Reader".
+	// Populated by annotateImplementsGraph after every package has been
+	// extracted; empty when the tree couldn't be type-checked.
+	Implements []string `json:"implements,omitempty"`
+	// SourcePos is the "file:line:col" of the type's declaration, per the
+	// token.FileSet extraction ran with. Empty for a dependency type
+	// resolved against its own throwaway FileSet (see resolveDependencyType).
+	SourcePos string `json:"sourcePos,omitempty"`
 }
 
 type IfaceApi struct {
@@ -70,6 +143,41 @@ type IfaceApi struct {
 	Methods        []FuncApi `json:"methods,omitempty"`
 	EntryPoint     bool      `json:"entryPoint,omitempty"`
 	ReExportedFrom string    `json:"reExportedFrom,omitempty"`
+	// TypeParams mirrors what extractTypeParams produces for structs, for
+	// a generic interface declaration ("type Set[T comparable] interface {
+	// ... }").
+	TypeParams []string `json:"typeParams,omitempty"`
+	// TypeParamDetails is the structured counterpart to TypeParams for a
+	// generic interface's own type parameter list; see TypeParamInfo.
+	TypeParamDetails []TypeParamInfo `json:"typeParamDetails,omitempty"`
+	// Constraint holds the type-set union elements of a Go 1.18+
+	// constraint interface ("interface { ~int | ~string }"), one entry per
+	// "|"-separated alternative. Plain method sets and embedded-interface
+	// elements (io.Reader, comparable) are unaffected and still reported
+	// via Methods/Embeds.
+	Constraint []ConstraintElem `json:"constraint,omitempty"`
+	// Implementers lists the module-local struct names annotateImplementsGraph
+	// found satisfying this interface on a pointer receiver. Only populated
+	// for module-local interfaces — IfaceApi has no entry for a stdlib
+	// interface like io.Reader, since that isn't itself part of the API
+	// being indexed; see StructApi.Implements for the inverse direction.
+	Implementers []string `json:"implementers,omitempty"`
+	// Contexts lists the build contexts this symbol was observed under, when
+	// extracted via a build-context matrix; see StructApi.Contexts.
+	Contexts []string `json:"contexts,omitempty"`
+	// SourcePos is the "file:line:col" of the interface's declaration; see
+	// StructApi.SourcePos.
+	SourcePos string `json:"sourcePos,omitempty"`
+}
+
+// ConstraintElem is one union alternative of a constraint interface's
+// type-set, e.g. the "~int" in "interface { ~int | ~string }".
+type ConstraintElem struct {
+	// Approx is true when the element carries Go 1.18's "~" approximation
+	// marker, meaning any type whose underlying type is Type also satisfies
+	// the constraint, not just Type itself.
+	Approx bool `json:"approx,omitempty"`
+	Type   string `json:"type"`
 }
 
 type FuncApi struct {
@@ -77,6 +185,10 @@ type FuncApi struct {
 	EntryPoint     bool     `json:"entryPoint,omitempty"`
 	ReExportedFrom string   `json:"reExportedFrom,omitempty"`
 	TypeParams     []string `json:"typeParams,omitempty"`
+	// TypeParamDetails is the structured counterpart to TypeParams for a
+	// generic function or method's own type parameter list; see
+	// TypeParamInfo.
+	TypeParamDetails []TypeParamInfo `json:"typeParamDetails,omitempty"`
 	Params         []ParameterInfo `json:"params,omitempty"`
 	Results        []ResultInfo `json:"results,omitempty"`
 	Sig            string   `json:"sig"`
@@ -86,6 +198,17 @@ type FuncApi struct {
 	Receiver       string   `json:"recv,omitempty"`
 	IsDeprecated   bool     `json:"deprecated,omitempty"`
 	DeprecatedMsg  string   `json:"deprecatedMsg,omitempty"`
+	// Contexts lists the build contexts this symbol was observed under, when
+	// extracted via a build-context matrix; see StructApi.Contexts.
+	Contexts []string `json:"contexts,omitempty"`
+	// PromotedFrom names the embedded field or interface this method was
+	// promoted from (e.g. "*azcore.Client"), when it isn't declared
+	// directly on the owning struct/interface. Empty for directly-declared
+	// methods.
+	PromotedFrom string `json:"promotedFrom,omitempty"`
+	// SourcePos is the "file:line:col" of the func/method declaration; see
+	// StructApi.SourcePos.
+	SourcePos string `json:"sourcePos,omitempty"`
 }
 
 type ParameterInfo struct {
@@ -115,6 +238,26 @@ type TypeApi struct {
 	IsDeprecated   bool   `json:"deprecated,omitempty"`
 	DeprecatedMsg  string `json:"deprecatedMsg,omitempty"`
 	ReExportedFrom string `json:"reExportedFrom,omitempty"`
+	// IsAlias is true for a "type Foo = Bar" alias declaration, as opposed
+	// to a defined type ("type Foo Bar") which gets its own identity and
+	// method set. AliasTarget repeats Type for an alias (kept separate so
+	// callers can match on AliasTarget without caring whether Type also
+	// carries a defined type's underlying form).
+	IsAlias     bool   `json:"isAlias,omitempty"`
+	AliasTarget string `json:"aliasTarget,omitempty"`
+	// TypeParams mirrors what extractTypeParams produces for structs,
+	// preserved on a generic alias ("type Foo[T any] = Bar[T]") so the
+	// alias's own type parameters aren't lost alongside AliasTarget.
+	TypeParams []string `json:"typeParams,omitempty"`
+	// TypeParamDetails is the structured counterpart to TypeParams; see
+	// TypeParamInfo.
+	TypeParamDetails []TypeParamInfo `json:"typeParamDetails,omitempty"`
+	// Contexts lists the build contexts this symbol was observed under, when
+	// extracted via a build-context matrix; see StructApi.Contexts.
+	Contexts []string `json:"contexts,omitempty"`
+	// SourcePos is the "file:line:col" of the type declaration; see
+	// StructApi.SourcePos.
+	SourcePos string `json:"sourcePos,omitempty"`
 }
 
 type ConstApi struct {
@@ -124,6 +267,9 @@ type ConstApi struct {
 	Doc           string `json:"doc,omitempty"`
 	IsDeprecated  bool   `json:"deprecated,omitempty"`
 	DeprecatedMsg string `json:"deprecatedMsg,omitempty"`
+	// Contexts lists the build contexts this symbol was observed under, when
+	// extracted via a build-context matrix; see StructApi.Contexts.
+	Contexts []string `json:"contexts,omitempty"`
 }
 
 type VarApi struct {
@@ -271,7 +417,7 @@ func main() {
 	args := os.Args[1:]
 
 	if len(args) < 1 {
-		fmt.Fprintln(os.Stderr, "Usage: go run graph_api.go <path> [--json] [--stub] [--pretty]")
+		fmt.Fprintln(os.Stderr, "Usage: go run graph_api.go <path> [--json] [--stub] [--canonical] [--format={stubs,json,index}] [--pretty] [--contexts <list>]")
 		fmt.Fprintln(os.Stderr, "       go run graph_api.go --usage <api_json_file> <samples_path>")
 		os.Exit(1)
 	}
@@ -291,66 +437,957 @@ func main() {
 				return args[i+1]
 			}
 		}
-		return ""
+		return ""
+	}
+
+	// Collect positional args (skip flags and their values)
+	usageFile := flagValue("usage")
+	var positional []string
+	valueFlags := map[string]bool{"--usage": true, "-usage": true, "--contexts": true, "-contexts": true, "--cache-dir": true, "-cache-dir": true, "--dep-allow": true, "-dep-allow": true, "--format": true, "-format": true}
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if valueFlags[a] {
+			i++ // skip the value
+			continue
+		}
+		if strings.HasPrefix(a, "--") || strings.HasPrefix(a, "-") {
+			continue
+		}
+		positional = append(positional, a)
+	}
+
+	// Handle --usage mode
+	if usageFile != "" {
+		if len(positional) < 1 {
+			fmt.Fprintln(os.Stderr, "Usage: go run graph_api.go --usage <api_json_file> <samples_path>")
+			os.Exit(1)
+		}
+		analyzeUsage(usageFile, positional[0])
+		return
+	}
+
+	// Handle --diff mode: compare two ApiIndex snapshots for backwards
+	// compatibility, mirroring what Go's own cmd/api does for the standard
+	// library.
+	if hasFlag("diff") {
+		if len(positional) < 2 {
+			fmt.Fprintln(os.Stderr, "Usage: go run graph_api.go --diff <old.json> <new.json> [--allow-breaking] [--json]")
+			os.Exit(1)
+		}
+		diffApiFiles(positional[0], positional[1], hasFlag("allow-breaking"), hasFlag("json"))
+		return
+	}
+
+	// Handle --analyzers mode: run pluggable go/analysis-style checkers over
+	// an ApiIndex. Bare --analyzers (no "=name1,name2" suffix) runs every
+	// registered analyzer.
+	analyzersFlag, hasAnalyzersFlag := "", false
+	for _, a := range args {
+		if a == "--analyzers" {
+			hasAnalyzersFlag = true
+		} else if strings.HasPrefix(a, "--analyzers=") {
+			hasAnalyzersFlag = true
+			analyzersFlag = strings.TrimPrefix(a, "--analyzers=")
+		}
+	}
+	if hasAnalyzersFlag {
+		if len(positional) < 1 {
+			fmt.Fprintln(os.Stderr, "Usage: go run graph_api.go --analyzers[=name1,name2] <api_json_file>")
+			os.Exit(1)
+		}
+		var names []string
+		if analyzersFlag != "" {
+			names = strings.Split(analyzersFlag, ",")
+		}
+		runAnalyzers(positional[0], names)
+		return
+	}
+
+	if len(positional) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: go run graph_api.go <path> [--json] [--stub] [--canonical] [--format={stubs,json,index}] [--pretty] [--contexts <list>]")
+		fmt.Fprintln(os.Stderr, "       go run graph_api.go --diff <old.json> <new.json> [--allow-breaking] [--json]")
+		os.Exit(1)
+	}
+
+	outputJson := hasFlag("json")
+	outputStub := hasFlag("stub")
+	outputCanonical := hasFlag("canonical")
+	formatFlag := flagValue("format")
+	for _, a := range args {
+		if strings.HasPrefix(a, "--format=") {
+			formatFlag = strings.TrimPrefix(a, "--format=")
+		}
+	}
+	pretty := hasFlag("pretty")
+	contextsFlag := flagValue("contexts")
+	cacheDisabled = hasFlag("no-cache")
+	cacheDirFlag = flagValue("cache-dir")
+	resolveDepsFlag = hasFlag("resolve-deps")
+	depAllowFlag = flagValue("dep-allow")
+
+	rootPath := positional[0]
+	if !outputJson && !outputStub && !outputCanonical {
+		outputStub = true
+	}
+
+	var api *ApiIndex
+	var err error
+	if hasFlag("contexts") {
+		contexts, cerr := parseContexts(contextsFlag)
+		if cerr != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", cerr)
+			os.Exit(1)
+		}
+		api, err = extractPackageMatrix(rootPath, contexts)
+	} else {
+		api, err = extractPackage(rootPath)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// --format={stubs,json,index} selects formatJSON/formatIndex's versioned
+	// export envelopes; it's an alternative to --json/--stub/--canonical
+	// (which predate it and still render the unversioned, un-indexed
+	// shapes) rather than a replacement for them.
+	if formatFlag != "" {
+		var output []byte
+		var ferr error
+		switch formatFlag {
+		case "json":
+			output, ferr = formatJSON(api)
+		case "index":
+			output, ferr = formatIndex(api)
+		case "stubs":
+			fmt.Println(formatStubs(api))
+			return
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown --format %q (want stubs, json, or index)\n", formatFlag)
+			os.Exit(1)
+		}
+		if ferr != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", ferr)
+			os.Exit(1)
+		}
+		if pretty {
+			var buf bytes.Buffer
+			if err := json.Indent(&buf, output, "", "  "); err == nil {
+				output = buf.Bytes()
+			}
+		}
+		fmt.Println(string(output))
+		return
+	}
+
+	if outputJson {
+		var output []byte
+		if pretty {
+			output, _ = json.MarshalIndent(api, "", "  ")
+		} else {
+			output, _ = json.Marshal(api)
+		}
+		fmt.Println(string(output))
+	} else if outputCanonical {
+		for _, line := range canonicalAPILines(api) {
+			fmt.Println(line)
+		}
+	} else {
+		fmt.Println(formatStubs(api))
+	}
+}
+
+// =============================================================================
+// API Diff (Backwards-Compatibility Checking)
+// =============================================================================
+
+// DiffChangeKind classifies a single difference between two ApiIndex
+// snapshots of the same package, mirroring the categories Go's own cmd/api
+// tool reports for the standard library.
+type DiffChangeKind string
+
+const (
+	DiffAdded              DiffChangeKind = "added"
+	DiffRemoved            DiffChangeKind = "removed"
+	DiffSignatureChanged   DiffChangeKind = "signature-changed"
+	DiffTypeChanged        DiffChangeKind = "type-changed"
+	DiffNewlyDeprecated    DiffChangeKind = "newly-deprecated"
+	DiffDeprecationRemoved DiffChangeKind = "deprecation-removed"
+	// DiffBreakingAddition is an "added" change that is nonetheless
+	// backwards-incompatible: a new method on an interface callers outside
+	// this module can implement, or a new struct field (exported or not),
+	// which breaks any existing unkeyed composite literal.
+	DiffBreakingAddition DiffChangeKind = "breaking-addition"
+	// DiffKindChanged is a name that kept the same identifier but changed
+	// declaration kind between snapshots, e.g. a struct replaced by an
+	// interface of the same name, or a type alias retargeted to a
+	// different underlying type.
+	DiffKindChanged DiffChangeKind = "kind-changed"
+)
+
+// DiffClassification is the three-way compatibility verdict a CI gate
+// actually cares about, collapsing the finer-grained DiffChangeKinds above.
+type DiffClassification string
+
+const (
+	ClassCompatible  DiffClassification = "compatible"
+	ClassBreaking    DiffClassification = "breaking"
+	ClassDeprecation DiffClassification = "deprecation"
+)
+
+// classification reports which of the three CI-facing buckets kind falls
+// into.
+func (k DiffChangeKind) classification() DiffClassification {
+	switch k {
+	case DiffNewlyDeprecated, DiffDeprecationRemoved:
+		return ClassDeprecation
+	case DiffRemoved, DiffSignatureChanged, DiffTypeChanged, DiffBreakingAddition, DiffKindChanged:
+		return ClassBreaking
+	default:
+		return ClassCompatible
+	}
+}
+
+// DiffChange is a single reported difference between two ApiIndex snapshots.
+type DiffChange struct {
+	Kind       DiffChangeKind `json:"kind"`
+	Package    string         `json:"package"`
+	Symbol     string         `json:"symbol"`
+	Detail     string         `json:"detail,omitempty"`
+	EntryPoint bool           `json:"entryPoint,omitempty"`
+}
+
+// DiffResult is the full outcome of comparing two ApiIndex snapshots.
+type DiffResult struct {
+	Changes  []DiffChange `json:"changes"`
+	Breaking bool         `json:"breaking"`
+}
+
+// isBreaking reports whether kind represents a backwards-incompatible change.
+func (k DiffChangeKind) isBreaking() bool {
+	return k.classification() == ClassBreaking
+}
+
+// ApiDiff is the externally-facing result of Diff: every change diffApiIndex
+// found, pre-sorted into the Breaking and Deprecations buckets so a CI gate
+// or a golden-file test doesn't have to re-derive classification itself.
+type ApiDiff struct {
+	Changes      []DiffChange `json:"changes"`
+	Breaking     []DiffChange `json:"breaking,omitempty"`
+	Deprecations []DiffChange `json:"deprecations,omitempty"`
+	IsBreaking   bool         `json:"isBreaking"`
+}
+
+// Diff compares old and new snapshots of the same module's ApiIndex and
+// classifies every change as Compatible, Breaking, or Deprecation. It is
+// meant to back a "commit a golden API file, fail CI when the freshly
+// extracted one diffs as breaking" workflow, following the model of Go's
+// own cmd/api allowlist tooling.
+func Diff(old, new *ApiIndex) *ApiDiff {
+	result := diffApiIndex(old, new)
+	d := &ApiDiff{Changes: result.Changes, IsBreaking: result.Breaking}
+	for _, c := range result.Changes {
+		switch c.Kind.classification() {
+		case ClassBreaking:
+			d.Breaking = append(d.Breaking, c)
+		case ClassDeprecation:
+			d.Deprecations = append(d.Deprecations, c)
+		}
+	}
+	return d
+}
+
+// diffApiFiles loads old and new ApiIndex snapshots from oldFile/newFile,
+// diffs them, prints the result (JSON if asJSON, otherwise human-readable),
+// and exits non-zero if any breaking change was found and allowBreaking is
+// false.
+func diffApiFiles(oldFile, newFile string, allowBreaking, asJSON bool) {
+	oldIndex, err := readApiIndexFile(oldFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", oldFile, err)
+		os.Exit(1)
+	}
+	newIndex, err := readApiIndexFile(newFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", newFile, err)
+		os.Exit(1)
+	}
+
+	result := Diff(oldIndex, newIndex)
+
+	if asJSON {
+		output, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(output))
+	} else {
+		for _, c := range result.Changes {
+			marker := ""
+			if c.EntryPoint {
+				marker = " (entry point)"
+			}
+			fmt.Printf("%s: %s.%s%s", c.Kind, c.Package, c.Symbol, marker)
+			if c.Detail != "" {
+				fmt.Printf(" — %s", c.Detail)
+			}
+			fmt.Println()
+		}
+		if len(result.Changes) == 0 {
+			fmt.Println("no API changes")
+		}
+		fmt.Printf("\n%d breaking, %d deprecation, %d total change(s)\n",
+			len(result.Breaking), len(result.Deprecations), len(result.Changes))
+	}
+
+	if result.IsBreaking && !allowBreaking {
+		os.Exit(1)
+	}
+}
+
+func readApiIndexFile(path string) (*ApiIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var idx ApiIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, err
+	}
+	return &idx, nil
+}
+
+// diffApiIndex compares oldIdx and newIdx package-by-package, classifying
+// each difference it finds among structs, interfaces, functions, types, and
+// constants. Changes are sorted by package then symbol for stable output.
+func diffApiIndex(oldIdx, newIdx *ApiIndex) DiffResult {
+	oldPkgs := make(map[string]PackageApi, len(oldIdx.Packages))
+	for _, p := range oldIdx.Packages {
+		oldPkgs[p.Name] = p
+	}
+	newPkgs := make(map[string]PackageApi, len(newIdx.Packages))
+	for _, p := range newIdx.Packages {
+		newPkgs[p.Name] = p
+	}
+
+	var changes []DiffChange
+	pkgNames := make(map[string]bool)
+	for name := range oldPkgs {
+		pkgNames[name] = true
+	}
+	for name := range newPkgs {
+		pkgNames[name] = true
+	}
+
+	for pkgName := range pkgNames {
+		changes = append(changes, diffFuncs(pkgName, oldPkgs[pkgName].Functions, newPkgs[pkgName].Functions)...)
+		changes = append(changes, diffStructs(pkgName, oldPkgs[pkgName].Structs, newPkgs[pkgName].Structs)...)
+		changes = append(changes, diffInterfaces(pkgName, oldPkgs[pkgName].Interfaces, newPkgs[pkgName].Interfaces)...)
+		changes = append(changes, diffTypes(pkgName, oldPkgs[pkgName].Types, newPkgs[pkgName].Types)...)
+		changes = append(changes, diffConsts(pkgName, oldPkgs[pkgName].Constants, newPkgs[pkgName].Constants)...)
+		changes = append(changes, diffSymbolKinds(pkgName, oldPkgs[pkgName], newPkgs[pkgName])...)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Package != changes[j].Package {
+			return changes[i].Package < changes[j].Package
+		}
+		return changes[i].Symbol < changes[j].Symbol
+	})
+
+	breaking := false
+	for _, c := range changes {
+		if c.Kind.isBreaking() {
+			breaking = true
+			break
+		}
+	}
+	return DiffResult{Changes: changes, Breaking: breaking}
+}
+
+func diffDeprecation(pkgName, symbol string, oldDeprecated, newDeprecated, entryPoint bool) []DiffChange {
+	if !oldDeprecated && newDeprecated {
+		return []DiffChange{{Kind: DiffNewlyDeprecated, Package: pkgName, Symbol: symbol, EntryPoint: entryPoint}}
+	}
+	if oldDeprecated && !newDeprecated {
+		return []DiffChange{{Kind: DiffDeprecationRemoved, Package: pkgName, Symbol: symbol, EntryPoint: entryPoint}}
+	}
+	return nil
+}
+
+func diffFuncs(pkgName string, oldFns, newFns []FuncApi) []DiffChange {
+	oldByName := make(map[string]FuncApi, len(oldFns))
+	for _, f := range oldFns {
+		oldByName[f.Name] = f
+	}
+	newByName := make(map[string]FuncApi, len(newFns))
+	for _, f := range newFns {
+		newByName[f.Name] = f
+	}
+
+	var changes []DiffChange
+	for name, oldFn := range oldByName {
+		newFn, ok := newByName[name]
+		if !ok {
+			changes = append(changes, DiffChange{Kind: DiffRemoved, Package: pkgName, Symbol: name, EntryPoint: oldFn.EntryPoint})
+			continue
+		}
+		if oldFn.Sig != newFn.Sig || oldFn.Ret != newFn.Ret {
+			changes = append(changes, DiffChange{
+				Kind: DiffSignatureChanged, Package: pkgName, Symbol: name, EntryPoint: newFn.EntryPoint,
+				Detail: fmt.Sprintf("%s -> %s", oldFn.Sig, newFn.Sig),
+			})
+		}
+		if oldFn.IsMethod && newFn.IsMethod && oldFn.Receiver != newFn.Receiver {
+			changes = append(changes, DiffChange{
+				Kind: DiffSignatureChanged, Package: pkgName, Symbol: name, EntryPoint: newFn.EntryPoint,
+				Detail: fmt.Sprintf("receiver %s -> %s", oldFn.Receiver, newFn.Receiver),
+			})
+		}
+		changes = append(changes, diffDeprecation(pkgName, name, oldFn.IsDeprecated, newFn.IsDeprecated, newFn.EntryPoint)...)
+	}
+	for name, newFn := range newByName {
+		if _, ok := oldByName[name]; !ok {
+			changes = append(changes, DiffChange{Kind: DiffAdded, Package: pkgName, Symbol: name, EntryPoint: newFn.EntryPoint})
+		}
+	}
+	return changes
+}
+
+func diffStructs(pkgName string, oldStructs, newStructs []StructApi) []DiffChange {
+	oldByName := make(map[string]StructApi, len(oldStructs))
+	for _, s := range oldStructs {
+		oldByName[s.Name] = s
+	}
+	newByName := make(map[string]StructApi, len(newStructs))
+	for _, s := range newStructs {
+		newByName[s.Name] = s
+	}
+
+	var changes []DiffChange
+	for name, oldS := range oldByName {
+		newS, ok := newByName[name]
+		if !ok {
+			changes = append(changes, DiffChange{Kind: DiffRemoved, Package: pkgName, Symbol: name, EntryPoint: oldS.EntryPoint})
+			continue
+		}
+		changes = append(changes, diffFields(pkgName, name, newS.EntryPoint, oldS.Fields, newS.Fields)...)
+		changes = append(changes, diffFuncs(pkgName, oldS.Methods, newS.Methods)...)
+		changes = append(changes, diffDeprecation(pkgName, name, oldS.IsDeprecated, newS.IsDeprecated, newS.EntryPoint)...)
+	}
+	for name, newS := range newByName {
+		if _, ok := oldByName[name]; !ok {
+			changes = append(changes, DiffChange{Kind: DiffAdded, Package: pkgName, Symbol: name, EntryPoint: newS.EntryPoint})
+		}
+	}
+	return changes
+}
+
+func diffFields(pkgName, structName string, entryPoint bool, oldFields, newFields []FieldApi) []DiffChange {
+	oldByName := make(map[string]FieldApi, len(oldFields))
+	for _, f := range oldFields {
+		oldByName[f.Name] = f
+	}
+	newByName := make(map[string]FieldApi, len(newFields))
+	for _, f := range newFields {
+		newByName[f.Name] = f
+	}
+
+	var changes []DiffChange
+	for name, oldF := range oldByName {
+		symbol := structName + "." + name
+		newF, ok := newByName[name]
+		if !ok {
+			changes = append(changes, DiffChange{Kind: DiffRemoved, Package: pkgName, Symbol: symbol, EntryPoint: entryPoint})
+			continue
+		}
+		if oldF.Type != newF.Type {
+			changes = append(changes, DiffChange{
+				Kind: DiffTypeChanged, Package: pkgName, Symbol: symbol, EntryPoint: entryPoint,
+				Detail: fmt.Sprintf("%s -> %s", oldF.Type, newF.Type),
+			})
+		}
+	}
+	for name := range newByName {
+		if _, ok := oldByName[name]; !ok {
+			// A new field, exported or not, breaks any existing unkeyed
+			// composite literal of this struct (Go counts every field,
+			// including unexported ones, when checking positional literals).
+			changes = append(changes, DiffChange{
+				Kind: DiffBreakingAddition, Package: pkgName, Symbol: structName + "." + name, EntryPoint: entryPoint,
+				Detail: "new field breaks existing unkeyed composite literals",
+			})
+		}
+	}
+	return changes
+}
+
+// isSealedInterface reports whether iface is sealed against external
+// implementations: it has an unexported method (the standard Go idiom for
+// preventing outside types from implementing it) or its doc comment says so
+// explicitly. Adding a method to a sealed interface can't break any real
+// implementation, since none exist outside this module. extractInterface
+// records only exported methods, so for an extracted index the
+// unexported-method check never matches and only the doc-comment check
+// applies.
+func isSealedInterface(iface IfaceApi) bool {
+	if strings.Contains(strings.ToLower(iface.Doc), "sealed") {
+		return true
+	}
+	for _, m := range iface.Methods {
+		if !ast.IsExported(m.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func diffInterfaces(pkgName string, oldIfaces, newIfaces []IfaceApi) []DiffChange {
+	oldByName := make(map[string]IfaceApi, len(oldIfaces))
+	for _, i := range oldIfaces {
+		oldByName[i.Name] = i
+	}
+	newByName := make(map[string]IfaceApi, len(newIfaces))
+	for _, i := range newIfaces {
+		newByName[i.Name] = i
+	}
+
+	var changes []DiffChange
+	for name, oldI := range oldByName {
+		newI, ok := newByName[name]
+		if !ok {
+			changes = append(changes, DiffChange{Kind: DiffRemoved, Package: pkgName, Symbol: name, EntryPoint: oldI.EntryPoint})
+			continue
+		}
+		methodChanges := diffFuncs(pkgName, oldI.Methods, newI.Methods)
+		if sealed := isSealedInterface(newI); !sealed {
+			// A non-sealed interface can be implemented by code outside
+			// this module; a new method it didn't previously require is
+			// therefore a breaking change for those implementers, not a
+			// harmless addition.
+			for idx := range methodChanges {
+				if methodChanges[idx].Kind == DiffAdded {
+					methodChanges[idx].Kind = DiffBreakingAddition
+					methodChanges[idx].Detail = "new method breaks existing external implementations"
+				}
+			}
+		}
+		changes = append(changes, methodChanges...)
+		changes = append(changes, diffDeprecation(pkgName, name, oldI.IsDeprecated, newI.IsDeprecated, newI.EntryPoint)...)
+	}
+	for name, newI := range newByName {
+		if _, ok := oldByName[name]; !ok {
+			changes = append(changes, DiffChange{Kind: DiffAdded, Package: pkgName, Symbol: name, EntryPoint: newI.EntryPoint})
+		}
+	}
+	return changes
+}
+
+func diffTypes(pkgName string, oldTypes, newTypes []TypeApi) []DiffChange {
+	oldByName := make(map[string]TypeApi, len(oldTypes))
+	for _, t := range oldTypes {
+		oldByName[t.Name] = t
+	}
+	newByName := make(map[string]TypeApi, len(newTypes))
+	for _, t := range newTypes {
+		newByName[t.Name] = t
+	}
+
+	var changes []DiffChange
+	for name, oldT := range oldByName {
+		newT, ok := newByName[name]
+		if !ok {
+			changes = append(changes, DiffChange{Kind: DiffRemoved, Package: pkgName, Symbol: name})
+			continue
+		}
+		if oldT.Type != newT.Type {
+			changes = append(changes, DiffChange{
+				Kind: DiffTypeChanged, Package: pkgName, Symbol: name,
+				Detail: fmt.Sprintf("%s -> %s", oldT.Type, newT.Type),
+			})
+		}
+		changes = append(changes, diffDeprecation(pkgName, name, oldT.IsDeprecated, newT.IsDeprecated, false)...)
+	}
+	for name := range newByName {
+		if _, ok := oldByName[name]; !ok {
+			changes = append(changes, DiffChange{Kind: DiffAdded, Package: pkgName, Symbol: name})
+		}
+	}
+	return changes
+}
+
+func diffConsts(pkgName string, oldConsts, newConsts []ConstApi) []DiffChange {
+	oldByName := make(map[string]ConstApi, len(oldConsts))
+	for _, c := range oldConsts {
+		oldByName[c.Name] = c
+	}
+	newByName := make(map[string]ConstApi, len(newConsts))
+	for _, c := range newConsts {
+		newByName[c.Name] = c
+	}
+
+	var changes []DiffChange
+	for name, oldC := range oldByName {
+		newC, ok := newByName[name]
+		if !ok {
+			changes = append(changes, DiffChange{Kind: DiffRemoved, Package: pkgName, Symbol: name})
+			continue
+		}
+		if oldC.Value != newC.Value {
+			changes = append(changes, DiffChange{
+				Kind: DiffTypeChanged, Package: pkgName, Symbol: name,
+				Detail: fmt.Sprintf("value changed: %s -> %s", oldC.Value, newC.Value),
+			})
+		}
+		changes = append(changes, diffDeprecation(pkgName, name, oldC.IsDeprecated, newC.IsDeprecated, false)...)
+	}
+	for name := range newByName {
+		if _, ok := oldByName[name]; !ok {
+			changes = append(changes, DiffChange{Kind: DiffAdded, Package: pkgName, Symbol: name})
+		}
+	}
+	return changes
+}
+
+// diffSymbolKinds flags a name that exists in both snapshots but as a
+// different declaration kind — a struct replaced by an interface, a type
+// alias retargeted to point at a different underlying type, and so on.
+// diffStructs/diffInterfaces/diffTypes only ever compare same-kind
+// collections against each other, so on their own they report a kind
+// change as an unrelated removal plus an unrelated addition; this adds one
+// kind-changed entry naming both kinds. The removal and addition are still
+// reported alongside it.
+func diffSymbolKinds(pkgName string, oldPkg, newPkg PackageApi) []DiffChange {
+	kindOf := func(pkg PackageApi) map[string]string {
+		kinds := make(map[string]string)
+		for _, s := range pkg.Structs {
+			kinds[s.Name] = "struct"
+		}
+		for _, i := range pkg.Interfaces {
+			kinds[i.Name] = "interface"
+		}
+		for _, t := range pkg.Types {
+			if t.IsAlias {
+				kinds[t.Name] = "alias:" + t.AliasTarget
+			} else {
+				kinds[t.Name] = "type"
+			}
+		}
+		return kinds
+	}
+	oldKinds := kindOf(oldPkg)
+	newKinds := kindOf(newPkg)
+
+	var changes []DiffChange
+	for name, oldKind := range oldKinds {
+		newKind, ok := newKinds[name]
+		if !ok || oldKind == newKind {
+			continue
+		}
+		oldBase, _, _ := strings.Cut(oldKind, ":")
+		newBase, _, _ := strings.Cut(newKind, ":")
+		if oldBase != newBase {
+			changes = append(changes, DiffChange{
+				Kind: DiffKindChanged, Package: pkgName, Symbol: name,
+				Detail: fmt.Sprintf("%s -> %s", oldBase, newBase),
+			})
+		} else if oldBase == "alias" {
+			changes = append(changes, DiffChange{
+				Kind: DiffKindChanged, Package: pkgName, Symbol: name,
+				Detail: fmt.Sprintf("alias retargeted: %s -> %s", oldKind, newKind),
+			})
+		}
+	}
+	return changes
+}
+
+// =============================================================================
+// Pluggable API Analyzers (go/analysis-style)
+// =============================================================================
+
+// Diagnostic is a single finding reported by an Analyzer. It carries the
+// category, message and suggested-fix fields of
+// golang.org/x/tools/go/analysis's Diagnostic but no source position, and
+// --analyzers prints these as one flat JSON array, not in that framework's
+// per-package -json layout.
+type Diagnostic struct {
+	Category     string `json:"category"`
+	Message      string `json:"message"`
+	Package      string `json:"package"`
+	Symbol       string `json:"symbol,omitempty"`
+	SuggestedFix string `json:"suggestedFix,omitempty"`
+}
+
+// AnalyzerPass is the input handed to every Analyzer.Run: the loaded
+// ApiIndex plus the cross-reference maps computed once for the whole run,
+// so analyzers don't each have to recompute reachability/implementer
+// info themselves.
+type AnalyzerPass struct {
+	Index                 *ApiIndex
+	AllTypeNames          map[string]bool
+	References            map[string]map[string]bool
+	ReferencedBy          map[string]int
+	InterfaceImplementers map[string][]StructApi
+}
+
+// Analyzer inspects an AnalyzerPass and reports Diagnostics. Name must be
+// unique among registered analyzers; it's what --analyzers=name1,name2
+// selects by.
+type Analyzer struct {
+	Name string
+	Doc  string
+	Run  func(pass *AnalyzerPass) []Diagnostic
+}
+
+var registeredAnalyzers = map[string]*Analyzer{}
+
+// RegisterAnalyzer adds a to the set --analyzers can select by name,
+// letting downstream users plug in their own checks without forking this
+// generator.
+func RegisterAnalyzer(a *Analyzer) {
+	registeredAnalyzers[a.Name] = a
+}
+
+func init() {
+	RegisterAnalyzer(unreferencedExportedAnalyzer)
+	RegisterAnalyzer(deprecatedInSignatureAnalyzer)
+	RegisterAnalyzer(interfaceWithoutImplAnalyzer)
+	RegisterAnalyzer(entrypointReturnsUnexportedAnalyzer)
+}
+
+var unreferencedExportedAnalyzer = &Analyzer{
+	Name: "unreferenced-exported",
+	Doc:  "reports exported non-entry-point structs that no other indexed type references; reachability from entry points is not traced",
+	Run: func(pass *AnalyzerPass) []Diagnostic {
+		var diags []Diagnostic
+		for _, pkg := range pass.Index.Packages {
+			for _, s := range pkg.Structs {
+				if s.EntryPoint || pass.ReferencedBy[s.Name] > 0 {
+					continue
+				}
+				diags = append(diags, Diagnostic{
+					Category: "unreferenced-exported", Package: pkg.Name, Symbol: s.Name,
+					Message: fmt.Sprintf("%s is exported but no other type in this index references it", s.Name),
+				})
+			}
+		}
+		return diags
+	},
+}
+
+var deprecatedInSignatureAnalyzer = &Analyzer{
+	Name: "deprecated-in-signature",
+	Doc:  "reports non-deprecated functions whose params/results mention a deprecated type",
+	Run: func(pass *AnalyzerPass) []Diagnostic {
+		deprecatedTypes := make(map[string]bool)
+		for _, pkg := range pass.Index.Packages {
+			for _, s := range pkg.Structs {
+				if s.IsDeprecated {
+					deprecatedTypes[s.Name] = true
+				}
+			}
+			for _, iface := range pkg.Interfaces {
+				if iface.IsDeprecated {
+					deprecatedTypes[iface.Name] = true
+				}
+			}
+			for _, t := range pkg.Types {
+				if t.IsDeprecated {
+					deprecatedTypes[t.Name] = true
+				}
+			}
+		}
+
+		var diags []Diagnostic
+		checkFunc := func(pkgName string, f FuncApi) {
+			if f.IsDeprecated {
+				return
+			}
+			tokens := make(map[string]bool)
+			tokenizeInto(f.Sig, tokens)
+			if f.Ret != "" {
+				tokenizeInto(f.Ret, tokens)
+			}
+			for token := range tokens {
+				if deprecatedTypes[token] {
+					diags = append(diags, Diagnostic{
+						Category: "deprecated-in-signature", Package: pkgName, Symbol: f.Name,
+						Message: fmt.Sprintf("%s is not deprecated but its signature mentions deprecated type %s", f.Name, token),
+					})
+				}
+			}
+		}
+		for _, pkg := range pass.Index.Packages {
+			for _, f := range pkg.Functions {
+				checkFunc(pkg.Name, f)
+			}
+			for _, s := range pkg.Structs {
+				for _, m := range s.Methods {
+					checkFunc(pkg.Name, m)
+				}
+			}
+		}
+		return diags
+	},
+}
+
+var interfaceWithoutImplAnalyzer = &Analyzer{
+	Name: "interface-without-impl",
+	Doc:  "reports interfaces with zero known implementers",
+	Run: func(pass *AnalyzerPass) []Diagnostic {
+		var diags []Diagnostic
+		for _, pkg := range pass.Index.Packages {
+			for _, iface := range pkg.Interfaces {
+				if len(iface.Methods) == 0 {
+					continue
+				}
+				if len(pass.InterfaceImplementers[iface.Name]) == 0 {
+					diags = append(diags, Diagnostic{
+						Category: "interface-without-impl", Package: pkg.Name, Symbol: iface.Name,
+						Message: fmt.Sprintf("%s has no known implementers in this index", iface.Name),
+					})
+				}
+			}
+		}
+		return diags
+	},
+}
+
+var entrypointReturnsUnexportedAnalyzer = &Analyzer{
+	Name: "entrypoint-returns-unexported",
+	Doc:  "reports exported entry-point factories whose return type isn't in the index",
+	Run: func(pass *AnalyzerPass) []Diagnostic {
+		definedTypes := make(map[string]bool)
+		for _, pkg := range pass.Index.Packages {
+			for _, t := range pkg.Types {
+				definedTypes[t.Name] = true
+			}
+		}
+		var diags []Diagnostic
+		for _, pkg := range pass.Index.Packages {
+			for _, f := range pkg.Functions {
+				if !f.EntryPoint || f.Ret == "" {
+					continue
+				}
+				retType := unwrapGoReturnType(f.Ret)
+				if isBuiltinType(retType) || isStdlibPackage(retType) {
+					continue
+				}
+				if !pass.AllTypeNames[retType] && !definedTypes[retType] {
+					diags = append(diags, Diagnostic{
+						Category: "entrypoint-returns-unexported", Package: pkg.Name, Symbol: f.Name,
+						Message: fmt.Sprintf("%s is an exported entry point returning %s, which isn't in this index", f.Name, f.Ret),
+					})
+				}
+			}
+		}
+		return diags
+	},
+}
+
+// buildAnalyzerPass computes the cross-reference maps every built-in
+// analyzer depends on, sharing the same references/referencedBy/
+// interfaceImplementers logic analyzeUsage uses for usage coverage.
+func buildAnalyzerPass(apiIndex *ApiIndex) *AnalyzerPass {
+	var allStructs []StructApi
+	var allInterfaces []IfaceApi
+	allTypeNames := make(map[string]bool)
+	for _, pkg := range apiIndex.Packages {
+		for _, s := range pkg.Structs {
+			allStructs = append(allStructs, s)
+			allTypeNames[s.Name] = true
+		}
+		for _, iface := range pkg.Interfaces {
+			allInterfaces = append(allInterfaces, iface)
+			allTypeNames[iface.Name] = true
+		}
 	}
 
-	// Collect positional args (skip flags and their values)
-	usageFile := flagValue("usage")
-	var positional []string
-	for i := 0; i < len(args); i++ {
-		a := args[i]
-		if a == "--usage" || a == "-usage" {
-			i++ // skip the value
-			continue
+	interfaceImplementers := make(map[string][]StructApi)
+	for _, iface := range allInterfaces {
+		methods := make(map[string]bool)
+		for _, m := range iface.Methods {
+			methods[m.Name] = true
 		}
-		if strings.HasPrefix(a, "--") || strings.HasPrefix(a, "-") {
+		if len(methods) == 0 {
 			continue
 		}
-		positional = append(positional, a)
-	}
-
-	// Handle --usage mode
-	if usageFile != "" {
-		if len(positional) < 1 {
-			fmt.Fprintln(os.Stderr, "Usage: go run graph_api.go --usage <api_json_file> <samples_path>")
-			os.Exit(1)
+		for _, s := range allStructs {
+			structMethods := make(map[string]bool)
+			for _, m := range s.Methods {
+				structMethods[m.Name] = true
+			}
+			implements := true
+			for methodName := range methods {
+				if !structMethods[methodName] {
+					implements = false
+					break
+				}
+			}
+			if implements {
+				interfaceImplementers[iface.Name] = append(interfaceImplementers[iface.Name], s)
+			}
 		}
-		analyzeUsage(usageFile, positional[0])
-		return
 	}
 
-	if len(positional) < 1 {
-		fmt.Fprintln(os.Stderr, "Usage: go run graph_api.go <path> [--json] [--stub] [--pretty]")
-		fmt.Fprintln(os.Stderr, "       go run graph_api.go --usage <api_json_file> <samples_path>")
-		os.Exit(1)
+	references := make(map[string]map[string]bool)
+	for _, s := range allStructs {
+		references[s.Name] = getReferencedTypes(s, allTypeNames)
+	}
+	for _, iface := range allInterfaces {
+		references[iface.Name] = getReferencedTypesForInterface(iface, allTypeNames)
 	}
 
-	outputJson := hasFlag("json")
-	outputStub := hasFlag("stub")
-	pretty := hasFlag("pretty")
+	referencedBy := make(map[string]int)
+	for typeName, refs := range references {
+		for ref := range refs {
+			if ref != typeName {
+				referencedBy[ref] = referencedBy[ref] + 1
+			}
+		}
+	}
 
-	rootPath := positional[0]
-	if !outputJson && !outputStub {
-		outputStub = true
+	return &AnalyzerPass{
+		Index:                 apiIndex,
+		AllTypeNames:          allTypeNames,
+		References:            references,
+		ReferencedBy:          referencedBy,
+		InterfaceImplementers: interfaceImplementers,
 	}
+}
 
-	api, err := extractPackage(rootPath)
+// runAnalyzers loads apiJsonFile, runs the analyzers named in names (or
+// every registered analyzer if names is empty) over it, and prints the
+// resulting diagnostics as JSON.
+func runAnalyzers(apiJsonFile string, names []string) {
+	apiIndex, err := readApiIndexFile(apiJsonFile)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", apiJsonFile, err)
 		os.Exit(1)
 	}
 
-	if outputJson {
-		var output []byte
-		if pretty {
-			output, _ = json.MarshalIndent(api, "", "  ")
-		} else {
-			output, _ = json.Marshal(api)
+	var analyzers []*Analyzer
+	if len(names) == 0 {
+		for _, a := range registeredAnalyzers {
+			analyzers = append(analyzers, a)
 		}
-		fmt.Println(string(output))
 	} else {
-		fmt.Println(formatStubs(api))
+		for _, name := range names {
+			a, ok := registeredAnalyzers[name]
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Error: unknown analyzer %q\n", name)
+				os.Exit(1)
+			}
+			analyzers = append(analyzers, a)
+		}
+	}
+	sort.Slice(analyzers, func(i, j int) bool { return analyzers[i].Name < analyzers[j].Name })
+
+	pass := buildAnalyzerPass(apiIndex)
+	var diags []Diagnostic
+	for _, a := range analyzers {
+		diags = append(diags, a.Run(pass)...)
 	}
+
+	output, _ := json.MarshalIndent(diags, "", "  ")
+	fmt.Println(string(output))
 }
 
 // ===== Usage Analysis Types =====
@@ -448,6 +1485,27 @@ func analyzeUsage(apiJsonFile, samplesPath string) {
 		references[iface.Name] = getReferencedTypesForInterface(iface, allTypeNames)
 	}
 
+	// When the samples module can be loaded with full type information,
+	// upgrade the tokenization-based references above to exact resolution
+	// through go/types, walking each type's declared fields and method
+	// signatures. This is strictly an improvement when it
+	// succeeds; when the module has no go.mod or fails to build (this tool
+	// also runs against bare source snapshots), loadTypesIndex errors and
+	// the tokenization-based references computed above stand as-is.
+	typesIdx, typesIdxErr := loadTypesIndex(samplesPath)
+	if typesIdxErr == nil {
+		for _, s := range allStructs {
+			if refs := typedReferencedTypeNames(typesIdx, s.Name, allTypeNames); len(refs) > 0 {
+				references[s.Name] = refs
+			}
+		}
+		for _, iface := range allInterfaces {
+			if refs := typedReferencedTypeNames(typesIdx, iface.Name, allTypeNames); len(refs) > 0 {
+				references[iface.Name] = refs
+			}
+		}
+	}
+
 	referencedBy := make(map[string]int)
 	for typeName, refs := range references {
 		for ref := range refs {
@@ -643,6 +1701,19 @@ func analyzeUsage(apiJsonFile, samplesPath string) {
 		// Build variable → client type map for this file
 		varTypes := buildVarTypeMap(f, clientNames, methodReturnTypeMap, functionReturnTypeMap, fieldTypeMap)
 
+		// Overlay go/types-resolved variable types where available. This
+		// catches everything the AST-pattern tracker above can miss: an
+		// aliased or dot-imported client, an embedded-field promotion, a
+		// generic instantiation (NewClient[Model](...)), or a client handed
+		// back through an errors.As-style out-parameter — all of which
+		// resolve correctly once we have the variable's real static type,
+		// rather than pattern-matching the expression that produced it.
+		if typesIdxErr == nil {
+			for name, typeName := range buildVarTypeMapTyped(typesIdx, file, clientNames) {
+				varTypes[name] = typeName
+			}
+		}
+
 		// Walk AST looking for method calls - resolve receiver type via var tracking first
 		ast.Inspect(f, func(n ast.Node) bool {
 			call, ok := n.(*ast.CallExpr)
@@ -756,6 +1827,33 @@ func analyzeUsage(apiJsonFile, samplesPath string) {
 		})
 	}
 
+	// When the samples module has full type information, overlay the exact
+	// Selections-based pass: it catches embedded-method promotion and
+	// method values the AST var-type tracker above can't follow, without
+	// needing whole-program SSA construction.
+	if typesIdxErr == nil {
+		for _, op := range typedCoveredOps(typesIdx, clientMethods, clientNames, absPath) {
+			key := op.Client + "." + op.Method
+			if !seenOps[key] {
+				seenOps[key] = true
+				covered = append(covered, op)
+			}
+		}
+	}
+
+	// When the samples module can be built, overlay a call-graph-based pass:
+	// it resolves calls through slices/maps, multi-hop returns, and generic
+	// functions that the AST var-type tracker above can't follow, and
+	// resolves interface calls to their concrete implementers via CHA
+	// instead of the ifaceToImplNames/implToIfaceNames name-matching below.
+	for _, op := range ssaCoveredOps(absPath, clientMethods) {
+		key := op.Client + "." + op.Method
+		if !seenOps[key] {
+			seenOps[key] = true
+			covered = append(covered, op)
+		}
+	}
+
 	// Build bidirectional interface ↔ struct mapping for coverage cross-referencing
 	ifaceToImplNames := make(map[string][]string)
 	implToIfaceNames := make(map[string][]string)
@@ -824,6 +1922,399 @@ func analyzeUsage(apiJsonFile, samplesPath string) {
 	fmt.Println(string(output))
 }
 
+// typesIndex wraps a golang.org/x/tools/go/packages load of a module with
+// full type information, used to resolve type references exactly (through
+// types.Info.Uses) instead of via string tokenization.
+type typesIndex struct {
+	pkgs []*packages.Package
+}
+
+// loadTypesIndex loads dir and its subpackages in
+// NeedTypes|NeedTypesInfo|NeedSyntax|NeedDeps mode. It returns an error
+// rather than panicking so callers can fall back to tokenization when the
+// module can't be loaded (no go.mod, unresolved deps, etc.) — this
+// generator also runs against bare source snapshots that were never meant
+// to build.
+func loadTypesIndex(dir string) (*typesIndex, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax | packages.NeedDeps | packages.NeedName | packages.NeedImports,
+		Dir:  dir,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) == 0 || packages.PrintErrors(pkgs) > 0 {
+		return nil, fmt.Errorf("graph_api: could not type-check packages under %s", dir)
+	}
+	return &typesIndex{pkgs: pkgs}, nil
+}
+
+// typedReferencedTypeNames resolves the types reachable from typeName's
+// declaration (fields, method signatures) through go/types and returns
+// those whose bare name appears in allTypeNames — the same universe
+// getReferencedTypes intersects against, so the two are drop-in
+// replacements for each other at the call site.
+func typedReferencedTypeNames(ti *typesIndex, typeName string, allTypeNames map[string]bool) map[string]bool {
+	refs := make(map[string]bool)
+	for _, pkg := range ti.pkgs {
+		if pkg.Types == nil {
+			continue
+		}
+		obj := pkg.Types.Scope().Lookup(typeName)
+		if obj == nil {
+			continue
+		}
+		named := make(map[string]bool)
+		visited := make(map[types.Type]bool)
+		walkTypeRefs(obj.Type(), named, visited)
+		if n, ok := obj.Type().(*types.Named); ok {
+			for i := 0; i < n.NumMethods(); i++ {
+				walkTypeRefs(n.Method(i).Type(), named, visited)
+			}
+		}
+		for qualified := range named {
+			bare := qualified
+			if i := strings.LastIndex(qualified, "."); i >= 0 {
+				bare = qualified[i+1:]
+			}
+			if allTypeNames[bare] {
+				refs[bare] = true
+			}
+		}
+	}
+	return refs
+}
+
+// walkTypeRefs recurses into t, recording every named type it finds as
+// "pkgPath.Name" (or bare "Name" when Pkg() is nil, e.g. builtins). visited
+// guards against infinite recursion through recursive type definitions.
+func walkTypeRefs(t types.Type, refs map[string]bool, visited map[types.Type]bool) {
+	if t == nil || visited[t] {
+		return
+	}
+	visited[t] = true
+	switch u := t.(type) {
+	case *types.Named:
+		name := u.Obj().Name()
+		if pkg := u.Obj().Pkg(); pkg != nil {
+			refs[pkg.Path()+"."+name] = true
+		} else {
+			refs[name] = true
+		}
+		walkTypeRefs(u.Underlying(), refs, visited)
+	case *types.Pointer:
+		walkTypeRefs(u.Elem(), refs, visited)
+	case *types.Slice:
+		walkTypeRefs(u.Elem(), refs, visited)
+	case *types.Array:
+		walkTypeRefs(u.Elem(), refs, visited)
+	case *types.Map:
+		walkTypeRefs(u.Key(), refs, visited)
+		walkTypeRefs(u.Elem(), refs, visited)
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			walkTypeRefs(u.Field(i).Type(), refs, visited)
+		}
+	case *types.Interface:
+		for i := 0; i < u.NumExplicitMethods(); i++ {
+			walkTypeRefs(u.ExplicitMethod(i).Type(), refs, visited)
+		}
+	case *types.Signature:
+		if u.Params() != nil {
+			for i := 0; i < u.Params().Len(); i++ {
+				walkTypeRefs(u.Params().At(i).Type(), refs, visited)
+			}
+		}
+		if u.Results() != nil {
+			for i := 0; i < u.Results().Len(); i++ {
+				walkTypeRefs(u.Results().At(i).Type(), refs, visited)
+			}
+		}
+	}
+}
+
+// stdlibInterfaces is a curated allow-list of well-known stdlib interfaces
+// worth recording satisfaction against even when the module being indexed
+// never imports them itself: loadStdlibInterfaces resolves each entry
+// directly via go/importer rather than relying on the analyzed module's
+// own import graph.
+var stdlibInterfaces = []struct {
+	pkgPath   string
+	name      string
+	qualified string // display name used in StructApi.Implements, e.g. "io.Reader"
+}{
+	{"io", "Reader", "io.Reader"},
+	{"io", "Writer", "io.Writer"},
+	{"io", "Closer", "io.Closer"},
+	{"fmt", "Stringer", "fmt.Stringer"},
+	{"encoding", "TextMarshaler", "encoding.TextMarshaler"},
+	{"context", "Context", "context.Context"},
+}
+
+// loadStdlibInterfaces resolves stdlibInterfaces' *types.Interface objects
+// via go/importer, plus the predeclared universe "error" interface. Entries
+// it can't resolve (importer unavailable, no compiled export data for that
+// GOROOT) are silently skipped — this overlay is best-effort, matching
+// every other go/types pass in this file.
+func loadStdlibInterfaces() map[string]*types.Interface {
+	found := make(map[string]*types.Interface)
+	imp := importer.Default()
+	pkgCache := make(map[string]*types.Package)
+	for _, entry := range stdlibInterfaces {
+		pkg, cached := pkgCache[entry.pkgPath]
+		if !cached {
+			var err error
+			pkg, err = imp.Import(entry.pkgPath)
+			if err != nil {
+				pkg = nil
+			}
+			pkgCache[entry.pkgPath] = pkg
+		}
+		if pkg == nil {
+			continue
+		}
+		obj := pkg.Scope().Lookup(entry.name)
+		if obj == nil {
+			continue
+		}
+		named, ok := obj.Type().(*types.Named)
+		if !ok {
+			continue
+		}
+		iface, ok := named.Underlying().(*types.Interface)
+		if !ok {
+			continue
+		}
+		found[entry.qualified] = iface
+	}
+	if errObj := types.Universe.Lookup("error"); errObj != nil {
+		if named, ok := errObj.Type().(*types.Named); ok {
+			if iface, ok := named.Underlying().(*types.Interface); ok {
+				found["error"] = iface
+			}
+		}
+	}
+	return found
+}
+
+// annotateImplementsGraph overlays types.Implements-based interface
+// satisfaction onto every StructApi/IfaceApi in pkgMap, populating
+// StructApi.Implements and the inverse IfaceApi.Implementers. Module-local
+// interfaces are matched against ctx.typesIdx, the same go/types load
+// extractStruct/extractInterface already use for embedded-method
+// promotion; stdlib interfaces come from loadStdlibInterfaces. No-op when
+// ctx.typesIdx is nil, i.e. the tree couldn't be type-checked.
+func annotateImplementsGraph(ctx *engineContext, pkgMap map[string]*PackageApi) {
+	if ctx.typesIdx == nil {
+		return
+	}
+
+	structs := make(map[string]*types.Named)
+	ifaces := make(map[string]*types.Interface)
+	for _, pkg := range ctx.typesIdx.pkgs {
+		if pkg.Types == nil {
+			continue
+		}
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			switch u := named.Underlying().(type) {
+			case *types.Struct:
+				structs[name] = named
+			case *types.Interface:
+				ifaces[name] = u
+			}
+		}
+	}
+	for qualified, iface := range loadStdlibInterfaces() {
+		ifaces[qualified] = iface
+	}
+	if len(structs) == 0 || len(ifaces) == 0 {
+		return
+	}
+
+	implementers := make(map[string][]string) // interface name -> struct names
+	satisfiedBy := make(map[string][]string)   // struct name -> interface names
+	for structName, named := range structs {
+		ptr := types.NewPointer(named)
+		for ifaceName, iface := range ifaces {
+			if !types.Implements(ptr, iface) && !types.Implements(named, iface) {
+				continue
+			}
+			satisfiedBy[structName] = append(satisfiedBy[structName], ifaceName)
+			implementers[ifaceName] = append(implementers[ifaceName], structName)
+		}
+	}
+
+	for _, pkgApi := range pkgMap {
+		for i := range pkgApi.Structs {
+			if satisfied := satisfiedBy[pkgApi.Structs[i].Name]; len(satisfied) > 0 {
+				sort.Strings(satisfied)
+				pkgApi.Structs[i].Implements = satisfied
+			}
+		}
+		for i := range pkgApi.Interfaces {
+			if names := implementers[pkgApi.Interfaces[i].Name]; len(names) > 0 {
+				sort.Strings(names)
+				pkgApi.Interfaces[i].Implementers = names
+			}
+		}
+	}
+}
+
+// ssaCoveredOps builds an ssa.Program for the package(s) under samplesPath,
+// computes a class-hierarchy-analysis call graph, and walks every edge
+// whose callee is a method on one of the types in clientMethods, recording
+// a CoveredOp per (receiverType, methodName) pair actually reached. Calls
+// through an interface resolve to every concrete implementer CHA finds,
+// which is what makes this more precise than the AST var-type tracker
+// above for values stored in slices/maps or returned through multiple
+// hops. Returns nil if samplesPath can't be loaded and type-checked (no
+// go.mod, unresolved deps, etc.) — callers treat that as "skip the overlay".
+func ssaCoveredOps(samplesPath string, clientMethods map[string]map[string]string) []CoveredOp {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
+			packages.NeedImports | packages.NeedDeps | packages.NeedTypes |
+			packages.NeedTypesSizes | packages.NeedSyntax | packages.NeedTypesInfo,
+		Dir: samplesPath,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil || len(pkgs) == 0 || packages.PrintErrors(pkgs) > 0 {
+		return nil
+	}
+
+	prog, _ := ssautil.AllPackages(pkgs, ssa.SanityCheckFunctions)
+	prog.Build()
+	cg := cha.CallGraph(prog)
+
+	var covered []CoveredOp
+	seen := make(map[string]bool)
+	err = callgraph.GraphVisitEdges(cg, func(edge *callgraph.Edge) error {
+		callee := edge.Callee.Func
+		if callee == nil || callee.Signature.Recv() == nil {
+			return nil
+		}
+		typeName := receiverTypeName(callee.Signature.Recv().Type())
+		methods, ok := clientMethods[typeName]
+		if !ok {
+			return nil
+		}
+		methodName := callee.Name()
+		if _, hasMethod := methods[methodName]; !hasMethod {
+			return nil
+		}
+		key := typeName + "." + methodName
+		if seen[key] || edge.Site == nil {
+			return nil
+		}
+		seen[key] = true
+		pos := prog.Fset.Position(edge.Site.Pos())
+		relPath, _ := filepath.Rel(samplesPath, pos.Filename)
+		covered = append(covered, CoveredOp{Client: typeName, Method: methodName, File: relPath, Line: pos.Line})
+		return nil
+	})
+	if err != nil {
+		return nil
+	}
+	return covered
+}
+
+// receiverTypeName returns the bare name of a (possibly pointer) receiver
+// type, e.g. "*SampleClient" and "SampleClient" both yield "SampleClient".
+func receiverTypeName(t types.Type) string {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return ""
+	}
+	return named.Obj().Name()
+}
+
+// resolveSelectionReceiver resolves sel's receiver to a known client type
+// name using ti's recorded *types.Selection for that call rather than name
+// overlap or local var tracking: Selections already accounts for embedded-
+// field promotion, pointer vs. value receivers, and method values, so a
+// method reached through an embedded type or passed as a func value
+// resolves to the outer (client) type exactly as Go itself would dispatch
+// it — cases buildVarTypeMapTyped and the chained-call strategies in
+// analyzeUsage's AST walk can't see, because they only track local
+// variables and literal receiver.Method() call shapes.
+func resolveSelectionReceiver(info *types.Info, sel *ast.SelectorExpr, clientNames map[string]bool) (string, bool) {
+	selection, ok := info.Selections[sel]
+	if !ok {
+		return "", false
+	}
+	name := clientTypeNameFromType(selection.Recv(), clientNames)
+	if name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+// typedCoveredOps is the Selections-based counterpart to the AST var-type
+// tracker in analyzeUsage: for every package ti loaded, it resolves each
+// call expression's receiver directly through go/types instead of replaying
+// buildVarTypeMapTyped/resolveExprType's pattern matching, so it also picks
+// up receivers reached through embedded-field promotion or stored as method
+// values — cases the pattern-based strategies miss because they only
+// recognize literal receiver.Method() shapes. Complements ssaCoveredOps,
+// which resolves interface dispatch across the whole program but doesn't
+// special-case promoted methods the way a per-call Selections lookup does.
+func typedCoveredOps(ti *typesIndex, clientMethods map[string]map[string]string, clientNames map[string]bool, samplesPath string) []CoveredOp {
+	var covered []CoveredOp
+	seen := make(map[string]bool)
+
+	for _, pkg := range ti.pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			ast.Inspect(file, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				sel, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				client, ok := resolveSelectionReceiver(pkg.TypesInfo, sel, clientNames)
+				if !ok {
+					return true
+				}
+				methodName := sel.Sel.Name
+				methods, ok := clientMethods[client]
+				if !ok {
+					return true
+				}
+				if _, hasMethod := methods[methodName]; !hasMethod {
+					return true
+				}
+				key := client + "." + methodName
+				if seen[key] {
+					return true
+				}
+				seen[key] = true
+				pos := pkg.Fset.Position(call.Pos())
+				relPath, _ := filepath.Rel(samplesPath, pos.Filename)
+				covered = append(covered, CoveredOp{Client: client, Method: methodName, File: relPath, Line: pos.Line})
+				return true
+			})
+		}
+	}
+	return covered
+}
+
 func getReferencedTypes(s StructApi, allTypeNames map[string]bool) map[string]bool {
 	refs := make(map[string]bool)
 
@@ -909,11 +2400,78 @@ func unwrapGoReturnType(ret string) string {
 	ret = strings.TrimPrefix(ret, "*")
 	ret = strings.TrimPrefix(ret, "[]")
 	ret = strings.TrimPrefix(ret, "*")
-	// Strip generic type args
-	if idx := strings.Index(ret, "["); idx > 0 {
-		ret = ret[:idx]
+	// Strip generic type args ("Client[Model]" -> "Client"). Parse the
+	// expression and unwrap the instantiation at the AST level so this
+	// stays correct for qualified and multi-argument cases
+	// ("pkg.Client[K, V]") instead of guessing from the first "[".
+	if idx := strings.IndexByte(ret, '['); idx > 0 {
+		if expr, err := parser.ParseExpr(ret); err == nil {
+			ret = formatExpr(stripGenericInstantiation(expr))
+		} else {
+			ret = ret[:idx]
+		}
+	}
+	return ret
+}
+
+// stripGenericInstantiation unwraps a generic instantiation expression
+// ("Client[Model]" or "Client[K, V]", represented by go/ast as an
+// IndexExpr or IndexListExpr) down to its base type expression. Non-generic
+// expressions are returned unchanged.
+func stripGenericInstantiation(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.IndexExpr:
+		return stripGenericInstantiation(e.X)
+	case *ast.IndexListExpr:
+		return stripGenericInstantiation(e.X)
+	default:
+		return expr
+	}
+}
+
+// recordInstantiation walks expr for IndexExpr/IndexListExpr nodes - a
+// generic type instantiated with concrete type arguments, like the
+// "Result[string, error]" in a field of that type - and records each one
+// found into ctx.instantiations, keyed by its rendered text so repeats
+// across the tree collapse to a single entry.
+func recordInstantiation(ctx *engineContext, expr ast.Expr) {
+	if ctx == nil || expr == nil {
+		return
+	}
+	ast.Inspect(expr, func(n ast.Node) bool {
+		var generic ast.Expr
+		var argExprs []ast.Expr
+		switch e := n.(type) {
+		case *ast.IndexExpr:
+			generic, argExprs = e.X, []ast.Expr{e.Index}
+		case *ast.IndexListExpr:
+			generic, argExprs = e.X, e.Indices
+		default:
+			return true
+		}
+		name := formatExpr(generic)
+		if name == "" {
+			return true
+		}
+		args := make([]string, len(argExprs))
+		for i, a := range argExprs {
+			args[i] = formatExpr(a)
+		}
+		key := name + "[" + strings.Join(args, ", ") + "]"
+		ctx.instantiations[key] = Instantiation{Generic: name, Args: args}
+		return true
+	})
+}
+
+// recordInstantiationsInFieldList calls recordInstantiation on every field's
+// type in fl, e.g. a function's parameter or result list.
+func recordInstantiationsInFieldList(ctx *engineContext, fl *ast.FieldList) {
+	if fl == nil {
+		return
+	}
+	for _, field := range fl.List {
+		recordInstantiation(ctx, field.Type)
 	}
-	return ret
 }
 
 // buildMethodReturnTypeMap builds a map of "OwnerType.MethodName" → return type
@@ -979,6 +2537,74 @@ func buildFieldTypeMap(structs []StructApi, clientNames map[string]bool) map[str
 	return m
 }
 
+// buildVarTypeMapTyped resolves variable -> client-type-name for filePath
+// straight from go/types, rather than pattern-matching the declaring
+// expression the way buildVarTypeMap does. Because it reads each
+// identifier's actual static type (types.Info.Defs/Uses), it correctly
+// handles clients reached through an aliased or dot-imported package,
+// embedded-field promotion, a generic instantiation, or any other
+// expression shape the AST patterns below don't special-case. Returns an
+// empty map (never an error) if filePath isn't found among ti's loaded
+// syntax — ti still comes from a best-effort packages.Load.
+func buildVarTypeMapTyped(ti *typesIndex, filePath string, clientNames map[string]bool) map[string]string {
+	varTypes := make(map[string]string)
+	for _, pkg := range ti.pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		var file *ast.File
+		for _, f := range pkg.Syntax {
+			if pkg.Fset.Position(f.Pos()).Filename == filePath {
+				file = f
+				break
+			}
+		}
+		if file == nil {
+			continue
+		}
+
+		record := func(ident *ast.Ident, t types.Type) {
+			if name := clientTypeNameFromType(t, clientNames); name != "" {
+				varTypes[ident.Name] = name
+			}
+		}
+		for ident, obj := range pkg.TypesInfo.Defs {
+			if v, ok := obj.(*types.Var); ok {
+				record(ident, v.Type())
+			}
+		}
+		for ident, obj := range pkg.TypesInfo.Uses {
+			if v, ok := obj.(*types.Var); ok {
+				record(ident, v.Type())
+			}
+		}
+		return varTypes
+	}
+	return varTypes
+}
+
+// clientTypeNameFromType strips a pointer (and, for a generic instantiation
+// such as Client[Model], the type arguments — types.Named.Obj().Name()
+// already reports the base name "Client" for those) and returns the bare
+// name if it names a known client type.
+func clientTypeNameFromType(t types.Type, clientNames map[string]bool) string {
+	if t == nil {
+		return ""
+	}
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return ""
+	}
+	name := named.Obj().Name()
+	if clientNames[name] {
+		return name
+	}
+	return ""
+}
+
 // buildVarTypeMap walks a Go AST file and builds a variable → client type map.
 //
 // Tracks patterns:
@@ -988,6 +2614,74 @@ func buildFieldTypeMap(structs []StructApi, clientNames map[string]bool) map[str
 //   - client := svc.BlobField            → client maps to BlobClient (field type map)
 //
 // All type resolution is driven by API index data — no name-based heuristics.
+// buildVarTypeMapTyped resolves variable -> client-type-name for filePath
+// straight from go/types, rather than pattern-matching the declaring
+// expression the way buildVarTypeMap does. Because it reads each
+// identifier's actual static type (types.Info.Defs/Uses), it correctly
+// handles clients reached through an aliased or dot-imported package,
+// embedded-field promotion, a generic instantiation, or any other
+// expression shape the AST patterns below don't special-case. Returns an
+// empty map (never an error) if filePath isn't found among ti's loaded
+// syntax — ti still comes from a best-effort packages.Load.
+func buildVarTypeMapTyped(ti *typesIndex, filePath string, clientNames map[string]bool) map[string]string {
+	varTypes := make(map[string]string)
+	for _, pkg := range ti.pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		var file *ast.File
+		for _, f := range pkg.Syntax {
+			if pkg.Fset.Position(f.Pos()).Filename == filePath {
+				file = f
+				break
+			}
+		}
+		if file == nil {
+			continue
+		}
+
+		record := func(ident *ast.Ident, t types.Type) {
+			if name := clientTypeNameFromType(t, clientNames); name != "" {
+				varTypes[ident.Name] = name
+			}
+		}
+		for ident, obj := range pkg.TypesInfo.Defs {
+			if v, ok := obj.(*types.Var); ok {
+				record(ident, v.Type())
+			}
+		}
+		for ident, obj := range pkg.TypesInfo.Uses {
+			if v, ok := obj.(*types.Var); ok {
+				record(ident, v.Type())
+			}
+		}
+		return varTypes
+	}
+	return varTypes
+}
+
+// clientTypeNameFromType strips a pointer (and, for a generic instantiation
+// such as Client[Model], the type arguments — types.Named.Obj().Name()
+// already reports the base name "Client" for those) and returns the bare
+// name if it names a known client type.
+func clientTypeNameFromType(t types.Type, clientNames map[string]bool) string {
+	if t == nil {
+		return ""
+	}
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return ""
+	}
+	name := named.Obj().Name()
+	if clientNames[name] {
+		return name
+	}
+	return ""
+}
+
 func buildVarTypeMap(f *ast.File, clientNames map[string]bool, methodRetMap, funcRetMap, fieldTypeMap map[string]string) map[string]string {
 	varTypes := make(map[string]string)
 
@@ -1050,14 +2744,18 @@ func buildVarTypeMap(f *ast.File, clientNames map[string]bool, methodRetMap, fun
 func resolveExprType(expr ast.Expr, clientNames map[string]bool, varTypes, methodRetMap, funcRetMap, fieldTypeMap map[string]string) string {
 	switch e := expr.(type) {
 	case *ast.CallExpr:
+		// Strip any explicit generic instantiation so a call like
+		// "NewClient[MyModel](...)" is still recognized as a call to
+		// "NewClient".
+		fun := stripGenericInstantiation(e.Fun)
 		// Function call: NewBlobClient(...)
-		if ident, ok := e.Fun.(*ast.Ident); ok {
+		if ident, ok := fun.(*ast.Ident); ok {
 			if retType, exists := funcRetMap[ident.Name]; exists {
 				return retType
 			}
 		}
 		// Method call: svc.GetBlobClient(...)
-		if sel, ok := e.Fun.(*ast.SelectorExpr); ok {
+		if sel, ok := fun.(*ast.SelectorExpr); ok {
 			methodName := sel.Sel.Name
 			if ident, ok := sel.X.(*ast.Ident); ok {
 				// Static factory: ClientType.Create(...)
@@ -1084,9 +2782,10 @@ func resolveExprType(expr ast.Expr, clientNames map[string]bool, varTypes, metho
 		}
 
 	case *ast.CompositeLit:
-		// Struct literal: BlobClient{...}
+		// Struct literal: BlobClient{...} or the generic instantiation
+		// BlobClient[Model]{...}.
 		if e.Type != nil {
-			typeName := unwrapGoReturnType(formatExpr(e.Type))
+			typeName := unwrapGoReturnType(formatExpr(stripGenericInstantiation(e.Type)))
 			if clientNames[typeName] {
 				return typeName
 			}
@@ -1112,7 +2811,16 @@ func resolveExprType(expr ast.Expr, clientNames map[string]bool, varTypes, metho
 	return ""
 }
 
+// extractPackage extracts an ApiIndex under the host's own build context.
 func extractPackage(rootPath string) (*ApiIndex, error) {
+	return extractPackageForContext(rootPath, nil)
+}
+
+// extractPackageForContext extracts an ApiIndex, filtering source files by
+// bc's build constraints (GOOS/GOARCH/CgoEnabled) via build.Context.MatchFile.
+// A nil bc extracts under the host's own build context, matching every file
+// parser.ParseDir would have matched before build-context filtering existed.
+func extractPackageForContext(rootPath string, bc *build.Context) (*ApiIndex, error) {
 	absPath, err := filepath.Abs(rootPath)
 	if err != nil {
 		return nil, err
@@ -1123,6 +2831,13 @@ func extractPackage(rootPath string) (*ApiIndex, error) {
 
 	// Create a fresh engine context for this engine run
 	ctx := newEngineContext()
+	// Best-effort go/types load of the whole tree, so extractStruct and
+	// extractInterface can promote methods gained through embedding onto
+	// the owning type. Ignored on failure — this generator also runs
+	// against bare source snapshots that were never meant to build.
+	if ti, err := loadTypesIndex(absPath); err == nil {
+		ctx.typesIdx = ti
+	}
 
 	// Find all Go packages
 	fset := token.NewFileSet()
@@ -1147,7 +2862,16 @@ func extractPackage(rootPath string) (*ApiIndex, error) {
 		}
 
 		pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
-			return !strings.HasSuffix(fi.Name(), "_test.go")
+			if strings.HasSuffix(fi.Name(), "_test.go") {
+				return false
+			}
+			if bc != nil {
+				match, err := bc.MatchFile(dir, fi.Name())
+				if err != nil || !match {
+					return false
+				}
+			}
+			return true
 		}, parser.ParseComments)
 		if err != nil {
 			continue
@@ -1160,11 +2884,29 @@ func extractPackage(rootPath string) (*ApiIndex, error) {
 			}
 		}
 
+		// Cache the expensive doc.New + extractPkg step (not the cheaper
+		// parser.ParseDir above, which collectImports still needs every run
+		// to keep the engine context's dependency graph accurate) keyed by
+		// the content hash of every file in this package directory.
+		var cacheKey string
+		if !cacheDisabled {
+			if key, kerr := packageCacheKey(dir, goFileNames(pkgs)); kerr == nil {
+				cacheKey = key
+			}
+		}
+
 		for pkgName, astPkg := range pkgs {
 			if strings.HasSuffix(pkgName, "_test") {
 				continue
 			}
 
+			if cacheKey != "" {
+				if cached, ok := readPackageCache(effectiveCacheDir(), cacheKey); ok {
+					packages[cached.Name] = cached
+					continue
+				}
+			}
+
 			docPkg := doc.New(astPkg, dir, doc.AllDecls)
 
 			pkgApi := extractPkg(ctx, docPkg, fset)
@@ -1177,10 +2919,19 @@ func extractPackage(rootPath string) (*ApiIndex, error) {
 				len(pkgApi.Functions) > 0 || len(pkgApi.Types) > 0 ||
 				len(pkgApi.Constants) > 0 || len(pkgApi.Variables) > 0 {
 				packages[pkgApi.Name] = pkgApi
+				ctx.xrefSources = append(ctx.xrefSources, xrefSource{pkgName: pkgApi.Name, doc: docPkg})
+				if cacheKey != "" {
+					writePackageCache(effectiveCacheDir(), cacheKey, *pkgApi)
+				}
 			}
 		}
 	}
 
+	// Overlay real interface satisfaction (types.Implements) onto the
+	// structs and interfaces just extracted. No-op when ctx.typesIdx
+	// couldn't be loaded, e.g. a bare source snapshot with no go.mod.
+	annotateImplementsGraph(ctx, packages)
+
 	// Mark entry points: types in the root package are the primary entry points
 	// The root package is the one whose relDir is "." or empty (directly in the
 	// module root), matching how Go users import the module.
@@ -1212,8 +2963,23 @@ func extractPackage(rootPath string) (*ApiIndex, error) {
 		Packages: sortedPkgs,
 	}
 
+	// Cross-reference index: who references whom, built from the package
+	// ASTs retained above.
+	if len(ctx.xrefSources) > 0 {
+		api.Xrefs = make(map[SymbolID][]SymbolRef)
+		for _, src := range ctx.xrefSources {
+			for sym, refs := range buildXrefs(src.pkgName, src.doc) {
+				api.Xrefs[sym] = refs
+			}
+		}
+	}
+
+	if len(ctx.instantiations) > 0 {
+		api.Instantiations = ctx.instantiations
+	}
+
 	// Resolve transitive dependencies
-	deps := resolveTransitiveDependencies(ctx)
+	deps := resolveTransitiveDependencies(ctx, packageName)
 	if len(deps) > 0 {
 		api.Dependencies = deps
 	}
@@ -1221,6 +2987,412 @@ func extractPackage(rootPath string) (*ApiIndex, error) {
 	return api, nil
 }
 
+// =============================================================================
+// Build Context Matrix
+// =============================================================================
+
+// BuildContext identifies a single GOOS/GOARCH/cgo combination to extract
+// the API under.
+type BuildContext struct {
+	GOOS       string
+	GOARCH     string
+	CgoEnabled bool
+}
+
+// String renders bc in the "GOOS/GOARCH" or "GOOS/GOARCH/cgo" form accepted
+// by --contexts.
+func (bc BuildContext) String() string {
+	s := bc.GOOS + "/" + bc.GOARCH
+	if bc.CgoEnabled {
+		s += "/cgo"
+	}
+	return s
+}
+
+// toGoBuildContext converts bc into the go/build.Context used to filter
+// source files during parsing.
+func (bc BuildContext) toGoBuildContext() *build.Context {
+	gobc := build.Default
+	gobc.GOOS = bc.GOOS
+	gobc.GOARCH = bc.GOARCH
+	gobc.CgoEnabled = bc.CgoEnabled
+	return &gobc
+}
+
+// defaultContextMatrix mirrors the tier-1 platform matrix covered by Go's
+// own cmd/api tool, with cgo on and off for the platforms where it matters.
+func defaultContextMatrix() []BuildContext {
+	return []BuildContext{
+		{"linux", "386", false}, {"linux", "386", true},
+		{"linux", "amd64", false}, {"linux", "amd64", true},
+		{"linux", "arm", false}, {"linux", "arm", true},
+		{"darwin", "amd64", false}, {"darwin", "amd64", true},
+		{"darwin", "arm64", false}, {"darwin", "arm64", true},
+		{"windows", "386", false},
+		{"windows", "amd64", false},
+		{"freebsd", "386", false}, {"freebsd", "386", true},
+		{"freebsd", "amd64", false}, {"freebsd", "amd64", true},
+	}
+}
+
+// parseContexts parses a --contexts flag value such as
+// "linux/amd64,darwin/arm64,windows/amd64,linux/arm/cgo" into BuildContexts.
+// An empty spec yields defaultContextMatrix.
+func parseContexts(spec string) ([]BuildContext, error) {
+	if strings.TrimSpace(spec) == "" {
+		return defaultContextMatrix(), nil
+	}
+	var result []BuildContext
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		fields := strings.Split(part, "/")
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid --contexts entry %q: expected GOOS/GOARCH[/cgo]", part)
+		}
+		bc := BuildContext{GOOS: fields[0], GOARCH: fields[1]}
+		if len(fields) > 2 {
+			if fields[2] != "cgo" {
+				return nil, fmt.Errorf("invalid --contexts entry %q: third segment must be \"cgo\"", part)
+			}
+			bc.CgoEnabled = true
+		}
+		result = append(result, bc)
+	}
+	return result, nil
+}
+
+// apiItemKey identifies a top-level API symbol stably across build contexts
+// so extraction results from different contexts can be unioned by identity
+// rather than by position.
+type apiItemKey struct {
+	pkg, kind, name, sig string
+}
+
+// extractPackageMatrix extracts an ApiIndex once per context in contexts,
+// unions the results into a single index keyed by (package, kind, name,
+// signature), and stamps each StructApi/FuncApi/ConstApi with the contexts
+// it was actually observed in. A symbol present under every context is left
+// with an empty Contexts (it is portable); one present under only some
+// contexts carries exactly those.
+func extractPackageMatrix(rootPath string, contexts []BuildContext) (*ApiIndex, error) {
+	if len(contexts) == 0 {
+		contexts = defaultContextMatrix()
+	}
+
+	var merged *ApiIndex
+	seenIn := make(map[apiItemKey][]string)
+
+	for _, bc := range contexts {
+		api, err := extractPackageForContext(rootPath, bc.toGoBuildContext())
+		if err != nil {
+			continue
+		}
+		for _, pkg := range api.Packages {
+			for _, s := range pkg.Structs {
+				k := apiItemKey{pkg.Name, "struct", s.Name, ""}
+				seenIn[k] = append(seenIn[k], bc.String())
+			}
+			for _, f := range pkg.Functions {
+				k := apiItemKey{pkg.Name, "func", f.Name, f.Sig}
+				seenIn[k] = append(seenIn[k], bc.String())
+			}
+			for _, c := range pkg.Constants {
+				k := apiItemKey{pkg.Name, "const", c.Name, ""}
+				seenIn[k] = append(seenIn[k], bc.String())
+			}
+			for _, t := range pkg.Types {
+				k := apiItemKey{pkg.Name, "type", t.Name, ""}
+				seenIn[k] = append(seenIn[k], bc.String())
+			}
+			for _, i := range pkg.Interfaces {
+				k := apiItemKey{pkg.Name, "iface", i.Name, ""}
+				seenIn[k] = append(seenIn[k], bc.String())
+			}
+		}
+		if merged == nil {
+			merged = api
+			continue
+		}
+		merged = unionApiIndex(merged, api)
+	}
+
+	if merged == nil {
+		return extractPackage(rootPath)
+	}
+
+	for _, bc := range contexts {
+		merged.Contexts = append(merged.Contexts, bc.String())
+	}
+
+	total := len(contexts)
+	for pi := range merged.Packages {
+		pkg := &merged.Packages[pi]
+		for si := range pkg.Structs {
+			k := apiItemKey{pkg.Name, "struct", pkg.Structs[si].Name, ""}
+			if ctxs := seenIn[k]; len(ctxs) < total {
+				pkg.Structs[si].Contexts = ctxs
+			}
+		}
+		for fi := range pkg.Functions {
+			k := apiItemKey{pkg.Name, "func", pkg.Functions[fi].Name, pkg.Functions[fi].Sig}
+			if ctxs := seenIn[k]; len(ctxs) < total {
+				pkg.Functions[fi].Contexts = ctxs
+			}
+		}
+		for ci := range pkg.Constants {
+			k := apiItemKey{pkg.Name, "const", pkg.Constants[ci].Name, ""}
+			if ctxs := seenIn[k]; len(ctxs) < total {
+				pkg.Constants[ci].Contexts = ctxs
+			}
+		}
+		for ti := range pkg.Types {
+			k := apiItemKey{pkg.Name, "type", pkg.Types[ti].Name, ""}
+			if ctxs := seenIn[k]; len(ctxs) < total {
+				pkg.Types[ti].Contexts = ctxs
+			}
+		}
+		for ii := range pkg.Interfaces {
+			k := apiItemKey{pkg.Name, "iface", pkg.Interfaces[ii].Name, ""}
+			if ctxs := seenIn[k]; len(ctxs) < total {
+				pkg.Interfaces[ii].Contexts = ctxs
+			}
+		}
+	}
+	return merged, nil
+}
+
+// unionApiIndex merges b into a, adding packages and symbols from b that a
+// doesn't already have (by name) and leaving a's existing symbols alone.
+// It is the identity-preserving merge used to fold together per-context
+// extraction runs in extractPackageMatrix.
+func unionApiIndex(a, b *ApiIndex) *ApiIndex {
+	indexByName := make(map[string]int, len(a.Packages))
+	for i := range a.Packages {
+		indexByName[a.Packages[i].Name] = i
+	}
+
+	for _, bp := range b.Packages {
+		idx, ok := indexByName[bp.Name]
+		if !ok {
+			a.Packages = append(a.Packages, bp)
+			indexByName[bp.Name] = len(a.Packages) - 1
+			continue
+		}
+		ap := &a.Packages[idx]
+
+		have := make(map[string]bool, len(ap.Structs))
+		for _, s := range ap.Structs {
+			have[s.Name] = true
+		}
+		for _, s := range bp.Structs {
+			if !have[s.Name] {
+				ap.Structs = append(ap.Structs, s)
+				have[s.Name] = true
+			}
+		}
+
+		haveFn := make(map[string]bool, len(ap.Functions))
+		for _, f := range ap.Functions {
+			haveFn[f.Name+"|"+f.Sig] = true
+		}
+		for _, f := range bp.Functions {
+			k := f.Name + "|" + f.Sig
+			if !haveFn[k] {
+				ap.Functions = append(ap.Functions, f)
+				haveFn[k] = true
+			}
+		}
+
+		haveConst := make(map[string]bool, len(ap.Constants))
+		for _, c := range ap.Constants {
+			haveConst[c.Name] = true
+		}
+		for _, c := range bp.Constants {
+			if !haveConst[c.Name] {
+				ap.Constants = append(ap.Constants, c)
+				haveConst[c.Name] = true
+			}
+		}
+
+		haveType := make(map[string]bool, len(ap.Types))
+		for _, t := range ap.Types {
+			haveType[t.Name] = true
+		}
+		for _, t := range bp.Types {
+			if !haveType[t.Name] {
+				ap.Types = append(ap.Types, t)
+				haveType[t.Name] = true
+			}
+		}
+
+		haveIface := make(map[string]bool, len(ap.Interfaces))
+		for _, i := range ap.Interfaces {
+			haveIface[i.Name] = true
+		}
+		for _, i := range bp.Interfaces {
+			if !haveIface[i.Name] {
+				ap.Interfaces = append(ap.Interfaces, i)
+				haveIface[i.Name] = true
+			}
+		}
+	}
+
+	sort.Slice(a.Packages, func(i, j int) bool { return a.Packages[i].Name < a.Packages[j].Name })
+	return a
+}
+
+// =============================================================================
+// Persistent Extraction Cache
+// =============================================================================
+
+// cacheDisabled and cacheDirFlag are set once from --no-cache/--cache-dir in
+// main before extraction begins; extractPackageForContext reads them via
+// effectiveCacheDir rather than threading a config value through every
+// caller in the extraction call chain.
+var (
+	cacheDisabled bool
+	cacheDirFlag  string
+)
+
+// toolVersion invalidates every cache entry when the running binary
+// changes — including a `go run` rebuild after editing this generator
+// itself, not just a Go toolchain upgrade — since os.Executable's content
+// is hashed fresh on every process start.
+var toolVersion = computeToolVersion()
+
+func computeToolVersion() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return goruntime.Version()
+	}
+	data, err := os.ReadFile(exe)
+	if err != nil {
+		return goruntime.Version()
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// effectiveCacheDir returns --cache-dir's value if set, otherwise
+// $XDG_CACHE_HOME/sdk-chat/api, falling back to ~/.cache/sdk-chat/api.
+func effectiveCacheDir() string {
+	if cacheDirFlag != "" {
+		return cacheDirFlag
+	}
+	if xdg := os.Getenv("XDG_CACHE_HOME"); xdg != "" {
+		return filepath.Join(xdg, "sdk-chat", "api")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "sdk-chat", "api")
+	}
+	return filepath.Join(home, ".cache", "sdk-chat", "api")
+}
+
+// cacheEntry is what's stored on disk for one package directory.
+type cacheEntry struct {
+	ToolVersion string     `json:"toolVersion"`
+	Package     PackageApi `json:"package"`
+}
+
+// goFileNames returns the full paths of every file parsed into pkgs, the
+// set packageCacheKey hashes to decide whether a package's cache entry is
+// still valid.
+func goFileNames(pkgs map[string]*ast.Package) []string {
+	var names []string
+	for _, astPkg := range pkgs {
+		for name := range astPkg.Files {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// fileDigest hashes path's (mtime, size, contents), so either touching or
+// editing a file invalidates any cache entry built from it.
+func fileDigest(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("%s|%d|%d|%x", path, info.ModTime().UnixNano(), info.Size(), sum), nil
+}
+
+// universeNamesDigest hashes the names in go/types.Universe, so a Go
+// upgrade that adds or removes a builtin (e.g. a new predeclared generic
+// constraint) invalidates every cache entry even if no source file changed.
+func universeNamesDigest() string {
+	var names []string
+	names = append(names, types.Universe.Names()...)
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// packageCacheKey derives a stable, content-addressed cache key for the
+// package made up of files, folding in each file's fileDigest (path, mtime,
+// size, contents), the go/types.Universe name set, and the running tool's
+// own content hash.
+func packageCacheKey(dir string, files []string) (string, error) {
+	sorted := append([]string{}, files...)
+	sort.Strings(sorted)
+
+	h := sha256.New()
+	io.WriteString(h, toolVersion)
+	io.WriteString(h, "\n")
+	io.WriteString(h, universeNamesDigest())
+	io.WriteString(h, "\n")
+	for _, f := range sorted {
+		digest, err := fileDigest(f)
+		if err != nil {
+			return "", err
+		}
+		io.WriteString(h, digest)
+		io.WriteString(h, "\n")
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func cacheEntryPath(cacheDir, key string) string {
+	return filepath.Join(cacheDir, key+".json")
+}
+
+// readPackageCache reads and validates the cache entry for key, returning
+// ok=false on any miss: file absent, unreadable, corrupt, or built by a
+// different toolVersion.
+func readPackageCache(cacheDir, key string) (*PackageApi, bool) {
+	data, err := os.ReadFile(cacheEntryPath(cacheDir, key))
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.ToolVersion != toolVersion {
+		return nil, false
+	}
+	pkg := entry.Package
+	return &pkg, true
+}
+
+// writePackageCache persists pkg's extraction result under key. Failures
+// are non-fatal — a cache write is a best-effort speedup, never load-bearing.
+func writePackageCache(cacheDir, key string, pkg PackageApi) {
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return
+	}
+	data, err := json.Marshal(cacheEntry{ToolVersion: toolVersion, Package: pkg})
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(cacheEntryPath(cacheDir, key), data, 0o644)
+}
+
 // =============================================================================
 // Transitive Dependency Resolution (AST-Based)
 // =============================================================================
@@ -1377,12 +3549,43 @@ func (c *TypeReferenceCollector) GetExternalRefs() map[string]bool {
 type engineContext struct {
 	typeCollector *TypeReferenceCollector
 	importMap     map[string]string
+	// typesIdx is a go/types load of the whole tree being extracted, used
+	// to promote methods gained through embedding onto the owning
+	// struct/interface and to resolve field types and implemented
+	// interfaces. Left nil (and those steps skipped) when the tree can't be
+	// type-checked, e.g. a bare source snapshot with no go.mod.
+	typesIdx *typesIndex
+	// xrefSources retains each package's parsed doc.Package, paired with the
+	// package name its PackageApi is ultimately filed under, so Xrefs can be
+	// built once every package in the tree has been extracted and named.
+	// Cache hits don't append here, since they skip re-parsing the AST.
+	xrefSources []xrefSource
+	// instantiations accumulates every generic instantiation ("Result[string,
+	// error]") seen in a field or result type across the whole extraction,
+	// keyed by its rendered form so repeats collapse to one entry. Flushed
+	// into ApiIndex.Instantiations once extraction completes.
+	instantiations map[string]Instantiation
+	// fset is the token.FileSet extractPkg was called with, stashed here so
+	// extractStruct/extractInterface/extractFunc can stamp a SourcePos
+	// ("file:line:col") on the symbols they build without widening their own
+	// signatures — the same "read it off ctx instead of threading a new
+	// parameter through the call chain" idiom as typesIdx above. nil for a
+	// throwaway context (e.g. resolveDependencyType's), in which case
+	// SourcePos is left empty.
+	fset *token.FileSet
+}
+
+// xrefSource is one package's AST, named and ready for buildXrefs.
+type xrefSource struct {
+	pkgName string
+	doc     *doc.Package
 }
 
 func newEngineContext() *engineContext {
 	return &engineContext{
-		typeCollector: NewTypeReferenceCollector(),
-		importMap:     make(map[string]string),
+		typeCollector:  NewTypeReferenceCollector(),
+		importMap:      make(map[string]string),
+		instantiations: make(map[string]Instantiation),
 	}
 }
 
@@ -1417,7 +3620,115 @@ func collectTypeReferences(ctx *engineContext) map[string]bool {
 	return ctx.typeCollector.GetExternalRefs()
 }
 
-func resolveTransitiveDependencies(ctx *engineContext) []DependencyInfo {
+// resolveDepsFlag and depAllowFlag are set once from --resolve-deps/--dep-allow
+// in main before extraction begins; resolveTransitiveDependencies reads them
+// via depAllowList rather than threading a config value through every caller
+// in the extraction call chain, mirroring cacheDisabled/cacheDirFlag above.
+var (
+	resolveDepsFlag bool
+	depAllowFlag    string
+)
+
+// depAllowList returns the import-path prefixes resolveTransitiveDependencies
+// is allowed to load source for. --dep-allow takes a comma-separated list;
+// with no flag, the default is the module being indexed itself (so a client
+// package that re-exports one of its own internal types as a dependency
+// still resolves) plus the Azure SDK's shared modules, since the common case
+// for this tool is an Azure SDK client whose methods return azcore/policy
+// types from elsewhere in the same module family.
+func depAllowList(modulePath string) []string {
+	if depAllowFlag != "" {
+		return strings.Split(depAllowFlag, ",")
+	}
+	return []string{modulePath, "github.com/Azure/azure-sdk-for-go"}
+}
+
+func isDepAllowed(pkgPath string, allow []string) bool {
+	for _, prefix := range allow {
+		if prefix != "" && strings.HasPrefix(pkgPath, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// depPkgCache caches resolveDependencyPackage's result per import path for
+// the process's lifetime, so a type referenced from ten call sites only
+// parses its defining package once.
+var depPkgCache = make(map[string]*doc.Package)
+
+// resolveDependencyPackage locates pkgPath's source directory via
+// go/build.Import (the same resolution `go build` itself uses: GOPATH,
+// module cache, vendor dir) and parses it into a *doc.Package, the same
+// intermediate extractPkg consumes for the module being indexed. Returns nil
+// if the package can't be found or parsed — like every other go/types-
+// adjacent pass in this file, dependency resolution is a best-effort overlay
+// that degrades to the bare-name placeholder on failure, not a hard error.
+func resolveDependencyPackage(pkgPath string) *doc.Package {
+	if cached, ok := depPkgCache[pkgPath]; ok {
+		return cached
+	}
+	bpkg, err := build.Import(pkgPath, "", 0)
+	if err != nil {
+		depPkgCache[pkgPath] = nil
+		return nil
+	}
+	fset := token.NewFileSet()
+	astPkgs, err := parser.ParseDir(fset, bpkg.Dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		depPkgCache[pkgPath] = nil
+		return nil
+	}
+	var astPkg *ast.Package
+	for name, p := range astPkgs {
+		if !strings.HasSuffix(name, "_test") {
+			astPkg = p
+			break
+		}
+	}
+	if astPkg == nil {
+		depPkgCache[pkgPath] = nil
+		return nil
+	}
+	docPkg := doc.New(astPkg, bpkg.ImportPath, doc.AllDecls)
+	depPkgCache[pkgPath] = docPkg
+	return docPkg
+}
+
+// resolveDependencyType looks up name among resolvedPkg's exported types and,
+// when found, extracts it the same way extractPkg extracts a module-local
+// type — real fields, methods, and doc comments instead of a bare name. The
+// *engineContext passed to extractStruct/extractInterface is the dependency
+// package's own throwaway context (not the indexed module's ctx): those
+// helpers use it for that package's own type collector, which must match
+// the types actually being extracted, not the caller's.
+func resolveDependencyType(resolvedPkg *doc.Package, name string) (*StructApi, *IfaceApi) {
+	depCtx := newEngineContext()
+	for _, t := range resolvedPkg.Types {
+		if t.Name != name {
+			continue
+		}
+		for _, spec := range t.Decl.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok || ts.Name.Name != name {
+				continue
+			}
+			switch st := ts.Type.(type) {
+			case *ast.StructType:
+				s := extractStruct(depCtx, t, st)
+				return &s, nil
+			case *ast.InterfaceType:
+				i := extractInterface(depCtx, t, st)
+				return nil, &i
+			}
+		}
+	}
+	return nil, nil
+}
+
+func resolveTransitiveDependencies(ctx *engineContext, modulePath string) []DependencyInfo {
 	refs := collectTypeReferences(ctx)
 	if len(refs) == 0 {
 		return nil
@@ -1442,10 +3753,29 @@ func resolveTransitiveDependencies(ctx *engineContext) []DependencyInfo {
 		}
 	}
 
+	// When enabled, load dependency packages on the allow-list so their
+	// types get real fields/methods/docs instead of bare names below.
+	var allow []string
+	resolvedPkgs := make(map[string]*doc.Package)
+	if resolveDepsFlag {
+		allow = depAllowList(modulePath)
+	}
+
 	// Convert to DependencyInfo list, classifying known interface embeds
 	var deps []DependencyInfo
 	for pkgPath, types := range depTypes {
 		dep := DependencyInfo{Package: pkgPath, IsStdlib: isStdlibPackage(pkgPath)}
+
+		var resolvedPkg *doc.Package
+		if resolveDepsFlag && !dep.IsStdlib && isDepAllowed(pkgPath, allow) {
+			if cached, ok := resolvedPkgs[pkgPath]; ok {
+				resolvedPkg = cached
+			} else {
+				resolvedPkg = resolveDependencyPackage(pkgPath)
+				resolvedPkgs[pkgPath] = resolvedPkg
+			}
+		}
+
 		for _, t := range types {
 			// Check if this type was seen as an interface embed (qualified name)
 			qualifiedName := ""
@@ -1463,9 +3793,20 @@ func resolveTransitiveDependencies(ctx *engineContext) []DependencyInfo {
 				qualifiedName = pkgPath + "." + t
 			}
 
-			if ctx.typeCollector.IsKnownInterface(qualifiedName) {
+			var resolvedStruct *StructApi
+			var resolvedIface *IfaceApi
+			if resolvedPkg != nil {
+				resolvedStruct, resolvedIface = resolveDependencyType(resolvedPkg, t)
+			}
+
+			switch {
+			case resolvedStruct != nil:
+				dep.Structs = append(dep.Structs, *resolvedStruct)
+			case resolvedIface != nil:
+				dep.Interfaces = append(dep.Interfaces, *resolvedIface)
+			case ctx.typeCollector.IsKnownInterface(qualifiedName):
 				dep.Interfaces = append(dep.Interfaces, IfaceApi{Name: t})
-			} else {
+			default:
 				dep.Types = append(dep.Types, TypeApi{Name: t})
 			}
 		}
@@ -1481,6 +3822,7 @@ func resolveTransitiveDependencies(ctx *engineContext) []DependencyInfo {
 }
 
 func extractPkg(ctx *engineContext, pkg *doc.Package, fset *token.FileSet) *PackageApi {
+	ctx.fset = fset
 	api := &PackageApi{
 		Doc: firstLine(pkg.Doc),
 	}
@@ -1540,25 +3882,39 @@ func extractPkg(ctx *engineContext, pkg *doc.Package, fset *token.FileSet) *Pack
 			case *ast.StructType:
 				s := extractStruct(ctx, t, st)
 				s.TypeParams = extractTypeParams(ts.TypeParams)
+				s.TypeParamDetails = extractTypeParamDetails(ts.TypeParams, ctx.typeCollector)
 				api.Structs = append(api.Structs, s)
 
 			case *ast.InterfaceType:
 				i := extractInterface(ctx, t, st)
+				i.TypeParams = extractTypeParams(ts.TypeParams)
+				i.TypeParamDetails = extractTypeParamDetails(ts.TypeParams, ctx.typeCollector)
 				api.Interfaces = append(api.Interfaces, i)
 
 			default:
-				// Type alias - collect type reference and register as defined
+				// Defined type ("type Foo Bar") or alias ("type Foo = Bar") -
+				// collect type reference and register as defined either way.
 				ctx.typeCollector.AddDefinedType(t.Name)
 				ctx.typeCollector.CollectFromExpr(ts.Type)
 				typeApi := TypeApi{
-					Name: t.Name,
-					Type: formatExpr(ts.Type),
-					Doc:  firstLine(t.Doc),
+					Name:      t.Name,
+					Type:      formatExpr(ts.Type),
+					Doc:       firstLine(t.Doc),
+					SourcePos: sourcePosOf(ctx.fset, t.Decl.Pos()),
 				}
 				if isDeprecated, deprecatedMsg := deprecationFromDoc(typeApi.Doc); isDeprecated {
 					typeApi.IsDeprecated = true
 					typeApi.DeprecatedMsg = deprecatedMsg
 				}
+				// ts.Assign is only valid (non-zero) for "=" alias form;
+				// that's the only AST-level signal distinguishing an alias
+				// from a defined type with the same shape of declaration.
+				if ts.Assign.IsValid() {
+					typeApi.IsAlias = true
+					typeApi.AliasTarget = typeApi.Type
+					typeApi.TypeParams = extractTypeParams(ts.TypeParams)
+					typeApi.TypeParamDetails = extractTypeParamDetails(ts.TypeParams, ctx.typeCollector)
+				}
 				api.Types = append(api.Types, typeApi)
 			}
 		}
@@ -1634,8 +3990,9 @@ func extractPkg(ctx *engineContext, pkg *doc.Package, fset *token.FileSet) *Pack
 
 func extractStruct(ctx *engineContext, t *doc.Type, st *ast.StructType) StructApi {
 	s := StructApi{
-		Name: t.Name,
-		Doc:  firstLine(t.Doc),
+		Name:      t.Name,
+		Doc:       firstLine(t.Doc),
+		SourcePos: sourcePosOf(ctx.fset, t.Decl.Pos()),
 	}
 	if isDeprecated, deprecatedMsg := deprecationFromDoc(s.Doc); isDeprecated {
 		s.IsDeprecated = true
@@ -1649,6 +4006,7 @@ func extractStruct(ctx *engineContext, t *doc.Type, st *ast.StructType) StructAp
 	for _, field := range st.Fields.List {
 		// Collect type references from AST
 		ctx.typeCollector.CollectFromExpr(field.Type)
+		recordInstantiation(ctx, field.Type)
 
 		if len(field.Names) == 0 {
 			// Embedded struct/interface (Go composition)
@@ -1674,6 +4032,20 @@ func extractStruct(ctx *engineContext, t *doc.Type, st *ast.StructType) StructAp
 		}
 	}
 
+	// Overlay go/types-resolved field types where available: formatExpr
+	// renders a field's raw AST spelling, so the same time.Duration field
+	// can come out as "time.Duration" in one file and "Duration" in
+	// another depending on how that file imported the package. The
+	// resolved form is import-alias-independent and always fully
+	// qualified, except for types defined in this same package.
+	if qualified := qualifiedFieldTypes(ctx, t.Name); qualified != nil {
+		for i := range s.Fields {
+			if qt, ok := qualified[s.Fields[i].Name]; ok {
+				s.Fields[i].Type = qt
+			}
+		}
+	}
+
 	// Methods
 	for _, m := range t.Methods {
 		if !isExported(m.Name) {
@@ -1692,13 +4064,217 @@ func extractStruct(ctx *engineContext, t *doc.Type, st *ast.StructType) StructAp
 		s.Methods = append(s.Methods, fn)
 	}
 
+	declared := make(map[string]bool, len(s.Methods))
+	for _, m := range s.Methods {
+		declared[m.Name] = true
+	}
+	s.Methods = append(s.Methods, promotedMethods(ctx, t.Name, declared)...)
+
 	return s
 }
 
+// typesSignatureParts renders sig's parameters and results in the same
+// "name Type, name2 Type2" / "(A, B)" convention formatParams/formatResults
+// use for AST-derived signatures, so a promoted method's Sig/Ret look
+// exactly like a directly-declared one's: types from home (the package of
+// the type the method is promoted onto) are unqualified, and every other
+// type carries its package name.
+func typesSignatureParts(sig *types.Signature, home *types.Package) (params, ret string) {
+	bareNames := func(p *types.Package) string {
+		if p == home {
+			return ""
+		}
+		return p.Name()
+	}
+
+	args := sig.Params()
+	var parts []string
+	for i := 0; i < args.Len(); i++ {
+		v := args.At(i)
+		typeStr := types.TypeString(v.Type(), bareNames)
+		if i == args.Len()-1 && sig.Variadic() {
+			typeStr = strings.Replace(typeStr, "[]", "...", 1)
+		}
+		if v.Name() != "" {
+			parts = append(parts, v.Name()+" "+typeStr)
+		} else {
+			parts = append(parts, typeStr)
+		}
+	}
+	params = strings.Join(parts, ", ")
+
+	results := sig.Results()
+	var retParts []string
+	for i := 0; i < results.Len(); i++ {
+		retParts = append(retParts, types.TypeString(results.At(i).Type(), bareNames))
+	}
+	switch len(retParts) {
+	case 0:
+		ret = ""
+	case 1:
+		ret = retParts[0]
+	default:
+		ret = "(" + strings.Join(retParts, ", ") + ")"
+	}
+	return params, ret
+}
+
+// embeddingTypeName names the type a promoted method's receiver actually
+// belongs to (the embedded field or interface), stripping any pointer.
+func embeddingTypeName(sig *types.Signature) string {
+	recv := sig.Recv()
+	if recv == nil {
+		return ""
+	}
+	t := recv.Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if named, ok := t.(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return types.TypeString(t, nil)
+}
+
+// qualifiedFieldTypes returns go/types-resolved, import-alias-independent
+// type strings for every field of the named struct typeName, keyed by field
+// name. It requires ctx.typesIdx; callers fall back to the AST-derived
+// FieldApi.Type (subject to however the source file spelled its imports)
+// when the type-checked package isn't available.
+func qualifiedFieldTypes(ctx *engineContext, typeName string) map[string]string {
+	if ctx.typesIdx == nil {
+		return nil
+	}
+	for _, pkg := range ctx.typesIdx.pkgs {
+		if pkg.Types == nil {
+			continue
+		}
+		obj := pkg.Types.Scope().Lookup(typeName)
+		if obj == nil {
+			continue
+		}
+		named, ok := obj.Type().(*types.Named)
+		if !ok {
+			continue
+		}
+		st, ok := named.Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+		pkgTypes := pkg.Types
+		qualifier := func(p *types.Package) string {
+			if p == pkgTypes {
+				return ""
+			}
+			return p.Name()
+		}
+		out := make(map[string]string, st.NumFields())
+		for i := 0; i < st.NumFields(); i++ {
+			f := st.Field(i)
+			out[f.Name()] = types.TypeString(f.Type(), qualifier)
+		}
+		return out
+	}
+	return nil
+}
+
+// promotedMethods returns the exported methods typeName gains through
+// embedding — embedded fields for a struct, embedded interfaces for an
+// interface — that aren't already in declared. It requires ctx.typesIdx, a
+// successful go/types load of the package under extraction; callers get no
+// promoted methods when that load failed, the same graceful degradation
+// loadTypesIndex's other consumers use.
+func promotedMethods(ctx *engineContext, typeName string, declared map[string]bool) []FuncApi {
+	if ctx.typesIdx == nil {
+		return nil
+	}
+	var named *types.Named
+	for _, pkg := range ctx.typesIdx.pkgs {
+		if pkg.Types == nil {
+			continue
+		}
+		obj := pkg.Types.Scope().Lookup(typeName)
+		if obj == nil {
+			continue
+		}
+		if n, ok := obj.Type().(*types.Named); ok {
+			named = n
+			break
+		}
+	}
+	if named == nil {
+		return nil
+	}
+
+	var out []FuncApi
+	// A pointer to an interface has an empty method set, so an interface
+	// uses its own method set; there every method not in declared came from
+	// an embedded interface.
+	_, isIface := named.Underlying().(*types.Interface)
+	mset := types.NewMethodSet(types.NewPointer(named))
+	if isIface {
+		mset = types.NewMethodSet(named)
+	}
+	for i := 0; i < mset.Len(); i++ {
+		sel := mset.At(i)
+		// len(sel.Index()) > 1 means the method was reached through at
+		// least one level of embedding rather than declared directly on
+		// named itself.
+		if !isIface && len(sel.Index()) <= 1 {
+			continue
+		}
+		fn, ok := sel.Obj().(*types.Func)
+		if !ok || !fn.Exported() || declared[fn.Name()] {
+			continue
+		}
+		sig, ok := fn.Type().(*types.Signature)
+		if !ok {
+			continue
+		}
+		params, ret := typesSignatureParts(sig, named.Obj().Pkg())
+		out = append(out, FuncApi{
+			Name:         fn.Name(),
+			Sig:          params,
+			Ret:          ret,
+			IsMethod:     true,
+			Receiver:     named.Obj().Name(),
+			PromotedFrom: embeddingTypeName(sig),
+		})
+	}
+	return out
+}
+
+// isConstraintElem reports whether expr is a Go 1.18+ type-set constraint
+// element — a "~T" approximation or a "A | B" union — rather than a plain
+// embedded interface name.
+func isConstraintElem(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.UnaryExpr:
+		return e.Op == token.TILDE
+	case *ast.BinaryExpr:
+		return e.Op == token.OR
+	default:
+		return false
+	}
+}
+
+// flattenConstraintElems recursively splits a "|"-chained union into its
+// individual ConstraintElem alternatives, unwrapping each "~T" marker.
+func flattenConstraintElems(expr ast.Expr) []ConstraintElem {
+	if bin, ok := expr.(*ast.BinaryExpr); ok && bin.Op == token.OR {
+		return append(flattenConstraintElems(bin.X), flattenConstraintElems(bin.Y)...)
+	}
+	if un, ok := expr.(*ast.UnaryExpr); ok && un.Op == token.TILDE {
+		return []ConstraintElem{{Approx: true, Type: formatExpr(un.X)}}
+	}
+	return []ConstraintElem{{Type: formatExpr(expr)}}
+}
+
 func extractInterface(ctx *engineContext, t *doc.Type, it *ast.InterfaceType) IfaceApi {
 	i := IfaceApi{
-		Name: t.Name,
-		Doc:  firstLine(t.Doc),
+		Name:      t.Name,
+		Doc:       firstLine(t.Doc),
+		SourcePos: sourcePosOf(ctx.fset, t.Decl.Pos()),
 	}
 	if isDeprecated, deprecatedMsg := deprecationFromDoc(i.Doc); isDeprecated {
 		i.IsDeprecated = true
@@ -1710,6 +4286,14 @@ func extractInterface(ctx *engineContext, t *doc.Type, it *ast.InterfaceType) If
 
 	for _, m := range it.Methods.List {
 		if len(m.Names) == 0 {
+			// Go 1.18+ type-set constraint: a union ("~int | ~string") or a
+			// single approximation element ("~int") rather than an embedded
+			// interface. Report these as structured Constraint elements
+			// instead of opaque Embeds strings.
+			if isConstraintElem(m.Type) {
+				i.Constraint = append(i.Constraint, flattenConstraintElems(m.Type)...)
+				continue
+			}
 			// Embedded interface (Go interface composition) — track as known interface
 			ctx.typeCollector.AddInterfaceEmbed(m.Type)
 			i.Embeds = append(i.Embeds, formatExpr(m.Type))
@@ -1737,6 +4321,12 @@ func extractInterface(ctx *engineContext, t *doc.Type, it *ast.InterfaceType) If
 		}
 	}
 
+	declared := make(map[string]bool, len(i.Methods))
+	for _, m := range i.Methods {
+		declared[m.Name] = true
+	}
+	i.Methods = append(i.Methods, promotedMethods(ctx, t.Name, declared)...)
+
 	return i
 }
 
@@ -1744,15 +4334,19 @@ func extractFunc(ctx *engineContext, decl *ast.FuncDecl, docStr string) FuncApi
 	// Collect type references from params and results
 	ctx.typeCollector.CollectFromFieldList(decl.Type.Params)
 	ctx.typeCollector.CollectFromFieldList(decl.Type.Results)
+	recordInstantiationsInFieldList(ctx, decl.Type.Params)
+	recordInstantiationsInFieldList(ctx, decl.Type.Results)
 
 	f := FuncApi{
-		Name:       decl.Name.Name,
-		Params:     extractParamInfos(decl.Type.Params),
-		Results:    engineResultInfos(decl.Type.Results),
-		Sig:        formatParams(decl.Type.Params),
-		Ret:        formatResults(decl.Type.Results),
-		Doc:        firstLine(docStr),
-		TypeParams: extractTypeParams(decl.Type.TypeParams),
+		Name:             decl.Name.Name,
+		Params:           extractParamInfos(decl.Type.Params),
+		Results:          engineResultInfos(decl.Type.Results),
+		Sig:              formatParams(decl.Type.Params),
+		Ret:              formatResults(decl.Type.Results),
+		Doc:              firstLine(docStr),
+		TypeParams:       extractTypeParams(decl.Type.TypeParams),
+		TypeParamDetails: extractTypeParamDetails(decl.Type.TypeParams, ctx.typeCollector),
+		SourcePos:        sourcePosOf(ctx.fset, decl.Pos()),
 	}
 	if isDeprecated, deprecatedMsg := deprecationFromDoc(f.Doc); isDeprecated {
 		f.IsDeprecated = true
@@ -1788,6 +4382,56 @@ func extractTypeParams(tpl *ast.FieldList) []string {
 	return params
 }
 
+// TypeParamInfo is the structured counterpart to extractTypeParams' plain
+// "T constraint" strings: the union elements of a type-set constraint
+// broken out via ConstraintElem, and the "~" approximation marker surfaced
+// separately for a single (non-union) approximated constraint like "~int".
+type TypeParamInfo struct {
+	Name string `json:"name"`
+	// Constraint is the constraint's surface text, e.g. "comparable" or
+	// "constraints.Ordered". Empty when Union is populated instead.
+	Constraint string `json:"constraint,omitempty"`
+	// Union holds the broken-out alternatives when the constraint is a
+	// type-set union ("~int | ~string"); see IfaceApi.Constraint.
+	Union []ConstraintElem `json:"union,omitempty"`
+	// Approximation is true when Constraint itself carries the "~"
+	// marker ("~int"), with no union involved.
+	Approximation bool `json:"approximation,omitempty"`
+}
+
+// extractTypeParamDetails is the structured counterpart to extractTypeParams:
+// it breaks a type-set union constraint into ConstraintElem alternatives
+// (matching how extractInterface handles a constraint interface's method
+// list) and registers each non-union constraint with collector so a
+// qualified constraint like "constraints.Ordered" is tracked as a
+// dependency the same as any other referenced type.
+func extractTypeParamDetails(tpl *ast.FieldList, collector *TypeReferenceCollector) []TypeParamInfo {
+	if tpl == nil || len(tpl.List) == 0 {
+		return nil
+	}
+	var params []TypeParamInfo
+	for _, field := range tpl.List {
+		for _, name := range field.Names {
+			p := TypeParamInfo{Name: name.Name}
+			if isConstraintElem(field.Type) {
+				p.Union = flattenConstraintElems(field.Type)
+				if len(p.Union) == 1 {
+					p.Constraint = p.Union[0].Type
+					p.Approximation = p.Union[0].Approx
+					p.Union = nil
+				}
+			} else {
+				p.Constraint = formatExpr(field.Type)
+				if collector != nil {
+					collector.CollectFromExpr(field.Type)
+				}
+			}
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
 func extractParamInfos(fl *ast.FieldList) []ParameterInfo {
 	if fl == nil || len(fl.List) == 0 {
 		return nil
@@ -1911,6 +4555,18 @@ func firstLine(s string) string {
 	return line
 }
 
+// sourcePosOf renders pos as "file:line:col" against fset, using the
+// basename rather than the full path so the result stays stable across
+// machines/checkouts. Returns "" when fset is nil (a throwaway context, or
+// pos itself is invalid) rather than a zero-value "-:0:0".
+func sourcePosOf(fset *token.FileSet, pos token.Pos) string {
+	if fset == nil || !pos.IsValid() {
+		return ""
+	}
+	p := fset.Position(pos)
+	return fmt.Sprintf("%s:%d:%d", filepath.Base(p.Filename), p.Line, p.Column)
+}
+
 func deprecationFromDoc(doc string) (bool, string) {
 	if doc == "" {
 		return false, ""
@@ -1945,6 +4601,387 @@ func detectPackageName(rootPath string) string {
 	return filepath.Base(rootPath)
 }
 
+// buildConstraintComment renders contexts — each a "GOOS/GOARCH" or
+// "GOOS/GOARCH/cgo" string, see BuildContext.String — as a classic
+// "// +build" line: platforms are ORed (space-separated), and a symbol's
+// GOOS/GOARCH/cgo within one platform are ANDed (comma-separated). Returns
+// "" when contexts is empty, i.e. the symbol is portable across the whole
+// requested matrix and needs no constraint at all.
+func buildConstraintComment(contexts []string) string {
+	if len(contexts) == 0 {
+		return ""
+	}
+	clauses := make([]string, 0, len(contexts))
+	for _, c := range contexts {
+		clauses = append(clauses, strings.ReplaceAll(strings.ReplaceAll(c, "/cgo", ",cgo"), "/", ","))
+	}
+	return "// +build " + strings.Join(clauses, " ") + "\n"
+}
+
+// canonicalAPILines renders idx as the one-line-per-symbol text form Go's
+// own cmd/api uses for its committed api/*.txt golden files (e.g. "pkg foo,
+// func Bar(int) error"), sorted for a stable diff. A project can commit this
+// output as api/next.txt and fail CI whenever it drifts from the committed
+// copy, without needing the JSON diff machinery at all.
+func canonicalAPILines(idx *ApiIndex) []string {
+	var lines []string
+	for _, pkg := range idx.Packages {
+		for _, c := range pkg.Constants {
+			lines = append(lines, fmt.Sprintf("pkg %s, const %s = %s", pkg.Name, c.Name, c.Value))
+		}
+		for _, v := range pkg.Variables {
+			lines = append(lines, fmt.Sprintf("pkg %s, var %s %s", pkg.Name, v.Name, v.Type))
+		}
+		for _, t := range pkg.Types {
+			if t.IsAlias {
+				lines = append(lines, fmt.Sprintf("pkg %s, type %s = %s", pkg.Name, t.Name, t.AliasTarget))
+			} else {
+				lines = append(lines, fmt.Sprintf("pkg %s, type %s %s", pkg.Name, t.Name, t.Type))
+			}
+		}
+		for _, i := range pkg.Interfaces {
+			lines = append(lines, fmt.Sprintf("pkg %s, type %s interface", pkg.Name, i.Name))
+			for _, m := range i.Methods {
+				lines = append(lines, fmt.Sprintf("pkg %s, method (%s) %s(%s) %s", pkg.Name, i.Name, m.Name, m.Sig, m.Ret))
+			}
+		}
+		for _, s := range pkg.Structs {
+			lines = append(lines, fmt.Sprintf("pkg %s, type %s struct", pkg.Name, s.Name))
+			for _, f := range s.Fields {
+				lines = append(lines, fmt.Sprintf("pkg %s, field (%s) %s %s", pkg.Name, s.Name, f.Name, f.Type))
+			}
+			for _, m := range s.Methods {
+				if m.IsMethod {
+					lines = append(lines, fmt.Sprintf("pkg %s, method (%s) %s(%s) %s", pkg.Name, m.Receiver, m.Name, m.Sig, m.Ret))
+				} else {
+					lines = append(lines, fmt.Sprintf("pkg %s, func %s(%s) %s", pkg.Name, m.Name, m.Sig, m.Ret))
+				}
+			}
+		}
+		for _, f := range pkg.Functions {
+			lines = append(lines, fmt.Sprintf("pkg %s, func %s(%s) %s", pkg.Name, f.Name, f.Sig, f.Ret))
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// buildXrefs scans pkg's exported func and method bodies for identifiers
+// that name other exported symbols in the same package, recording each as an
+// edge from the referencing symbol to the referenced one. It only matches
+// direct *ast.Ident occurrences against the package's known exported names,
+// so a call reached through a local variable of unresolved type, or a
+// cross-package reference, is not tracked. Methods are known only as
+// "Type.Method", which no single identifier spells, so a method call is
+// never recorded as a reference.
+func buildXrefs(pkgName string, pkg *doc.Package) map[SymbolID][]SymbolRef {
+	known := make(map[string]SymbolID)
+	for _, f := range pkg.Funcs {
+		if isExported(f.Name) {
+			known[f.Name] = SymbolID(pkgName + "." + f.Name)
+		}
+	}
+	for _, t := range pkg.Types {
+		if isExported(t.Name) {
+			known[t.Name] = SymbolID(pkgName + "." + t.Name)
+		}
+		for _, f := range t.Funcs {
+			if isExported(f.Name) {
+				known[f.Name] = SymbolID(pkgName + "." + f.Name)
+			}
+		}
+		for _, m := range t.Methods {
+			if isExported(m.Name) {
+				known[t.Name+"."+m.Name] = SymbolID(pkgName + "." + t.Name + "." + m.Name)
+			}
+		}
+	}
+
+	xrefs := make(map[SymbolID][]SymbolRef)
+	walk := func(self SymbolID, decl *ast.FuncDecl) {
+		if decl == nil || decl.Body == nil {
+			return
+		}
+		seen := make(map[SymbolID]bool)
+		ast.Inspect(decl.Body, func(n ast.Node) bool {
+			ident, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			if sym, ok := known[ident.Name]; ok && sym != self && !seen[sym] {
+				seen[sym] = true
+				xrefs[self] = append(xrefs[self], SymbolRef{Symbol: sym})
+			}
+			return true
+		})
+	}
+
+	for _, f := range pkg.Funcs {
+		if isExported(f.Name) {
+			walk(SymbolID(pkgName+"."+f.Name), f.Decl)
+		}
+	}
+	for _, t := range pkg.Types {
+		for _, f := range t.Funcs {
+			if isExported(f.Name) {
+				walk(SymbolID(pkgName+"."+f.Name), f.Decl)
+			}
+		}
+		for _, m := range t.Methods {
+			if isExported(m.Name) {
+				walk(SymbolID(pkgName+"."+t.Name+"."+m.Name), m.Decl)
+			}
+		}
+	}
+	return xrefs
+}
+
+// CallersOf returns the FuncApi entries (functions and methods alike) whose
+// body references name, the bare symbol name as it appears in Xrefs (e.g.
+// "NewClient", not "pkg.NewClient"). Xrefs records only package-level
+// funcs/types as referenced symbols, so a method name finds no callers.
+// The search is package-qualified
+// implicitly: only symbols recorded in idx.Xrefs are considered, so cross-
+// package callers outside this extraction are never returned.
+func (idx *ApiIndex) CallersOf(name string) []FuncApi {
+	var callerIDs []SymbolID
+	for caller, refs := range idx.Xrefs {
+		for _, ref := range refs {
+			if strings.HasSuffix(string(ref.Symbol), "."+name) {
+				callerIDs = append(callerIDs, caller)
+				break
+			}
+		}
+	}
+	if len(callerIDs) == 0 {
+		return nil
+	}
+	byID := make(map[SymbolID]bool, len(callerIDs))
+	for _, id := range callerIDs {
+		byID[id] = true
+	}
+
+	var callers []FuncApi
+	for _, pkg := range idx.Packages {
+		for _, f := range pkg.Functions {
+			if byID[SymbolID(pkg.Name+"."+f.Name)] {
+				callers = append(callers, f)
+			}
+		}
+		for _, s := range pkg.Structs {
+			for _, m := range s.Methods {
+				if m.IsMethod && byID[SymbolID(pkg.Name+"."+m.Receiver+"."+m.Name)] {
+					callers = append(callers, m)
+				}
+			}
+		}
+	}
+	return callers
+}
+
+// =============================================================================
+// Export-Data Formats (formatJSON / formatIndex)
+// =============================================================================
+
+// exportSchemaVersion is bumped whenever formatJSON's or formatIndex's shape
+// changes in a way that would break a pinned consumer (field removed/
+// renamed, a type table's ID assignment changed, etc.) — purely additive
+// fields don't need a bump.
+const exportSchemaVersion = "1"
+
+// exportHeader is the envelope both export formats lead with, so a consumer
+// can pin to a schema version and identify which module/build produced the
+// file before parsing the payload.
+type exportHeader struct {
+	SchemaVersion string `json:"schemaVersion"`
+	Module        string `json:"module"`
+	// Generator is "sdk-chat-" followed by toolVersion (this binary's own
+	// content hash, since a
+	// //go:build ignore script has no semantic-version release train) —
+	// enough to tell two exports came from different generator builds even
+	// without a human-readable version number.
+	Generator string `json:"generator"`
+}
+
+func newExportHeader(module string) exportHeader {
+	return exportHeader{SchemaVersion: exportSchemaVersion, Module: module, Generator: "sdk-chat-" + toolVersion}
+}
+
+// formatJSON wraps api in the versioned envelope and marshals it whole, with
+// every cross-reference still stored as repeated strings (type names,
+// signatures) rather than the integer IDs formatIndex uses. This is the
+// format for a consumer that just wants ApiIndex's existing shape plus a
+// schema version to pin to.
+func formatJSON(api *ApiIndex) ([]byte, error) {
+	type envelope struct {
+		exportHeader
+		*ApiIndex
+	}
+	return json.Marshal(envelope{exportHeader: newExportHeader(api.Package), ApiIndex: api})
+}
+
+// indexedType is one row of formatIndex's types table: a struct, interface,
+// or defined/alias type, identified by Package plus Name, so the same bare
+// name in two packages gets two rows with distinct IDs. A field or
+// signature that names such a type resolves (by bare name, see
+// bareTypeName) to the first of them.
+type indexedType struct {
+	ID         int                `json:"id"`
+	Name       string             `json:"name"`
+	Package    string             `json:"package"`
+	Kind       string             `json:"kind"` // "struct", "interface", or "type"
+	Doc        string             `json:"doc,omitempty"`
+	SourcePos  string             `json:"sourcePos,omitempty"`
+	Fields     []indexedField     `json:"fields,omitempty"`
+	Methods    []indexedSignature `json:"methods,omitempty"`
+	Underlying string             `json:"underlying,omitempty"` // non-struct/interface defined types and aliases
+}
+
+// indexedField mirrors FieldApi, with TypeID resolved against the types
+// table when Type's bare name (stripped of pointer/slice/map syntax and any
+// package qualifier) matches an indexed type; TypeID is 0 (omitted) for a
+// builtin or unresolved type, in which case Type is the only information a
+// consumer has, same as today's rendered-text fields.
+type indexedField struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	TypeID int    `json:"typeId,omitempty"`
+	Doc    string `json:"doc,omitempty"`
+}
+
+// indexedSignature mirrors FuncApi at the granularity formatIndex cross-
+// references: params/results by rendered type plus a best-effort TypeID.
+type indexedSignature struct {
+	Name    string              `json:"name"`
+	Params  []indexedTypedValue `json:"params,omitempty"`
+	Results []indexedTypedValue `json:"results,omitempty"`
+	Doc     string              `json:"doc,omitempty"`
+}
+
+type indexedTypedValue struct {
+	Name   string `json:"name,omitempty"`
+	Type   string `json:"type"`
+	TypeID int    `json:"typeId,omitempty"`
+}
+
+// bareTypeName strips the syntax formatExpr/formatParams render around a
+// type reference (pointer, slice, map value, variadic) and any package
+// qualifier, leaving just the identifier a types-table lookup can match
+// against — e.g. "[]*azcore.Response" -> "Response". Best-effort, like every
+// other name-based resolution pass in this file: it can't disambiguate two
+// identically-named types in different packages without more context than a
+// rendered string carries.
+func bareTypeName(raw string) string {
+	s := strings.TrimPrefix(raw, "...")
+	for strings.HasPrefix(s, "*") || strings.HasPrefix(s, "[]") || strings.HasPrefix(s, "map[") {
+		s = strings.TrimPrefix(s, "*")
+		s = strings.TrimPrefix(s, "[]")
+		if strings.HasPrefix(s, "map[") {
+			i := strings.IndexByte(s, ']')
+			if i < 0 {
+				break
+			}
+			s = s[i+1:]
+		}
+	}
+	if i := strings.LastIndexByte(s, '.'); i >= 0 {
+		s = s[i+1:]
+	}
+	return s
+}
+
+func resolveTypeID(raw string, typeIDs map[string]int) int {
+	return typeIDs[bareTypeName(raw)]
+}
+
+// formatIndex renders api as a compact, cross-referenced export: every
+// struct/interface/defined-type becomes one row in a types table keyed by a
+// sequential integer ID, and every field/param/result that names one of
+// those types carries that ID alongside its rendered text — so a consumer
+// walking the graph doesn't need to re-parse type strings to follow an edge.
+// Falls back to the bare rendered type (TypeID 0) for anything that isn't a
+// module-local type (builtins, stdlib, unresolved generics), the same
+// degrade-gracefully approach every go/types overlay in this file takes.
+func formatIndex(api *ApiIndex) ([]byte, error) {
+	typeIDs := make(map[string]int) // bare name -> ID of the first type with that name
+	rowIDs := make(map[string]int)  // "pkg.Name" -> that type's own ID
+	var types []indexedType
+	nextID := 1
+	assign := func(pkg, name string) int {
+		id := nextID
+		nextID++
+		rowIDs[pkg+"."+name] = id
+		if _, ok := typeIDs[name]; !ok {
+			typeIDs[name] = id
+		}
+		return id
+	}
+
+	// First pass: reserve an ID for every named type so forward references
+	// (a field whose type is declared later in the same package) resolve.
+	for _, pkg := range api.Packages {
+		for _, s := range pkg.Structs {
+			assign(pkg.Name, s.Name)
+		}
+		for _, ifc := range pkg.Interfaces {
+			assign(pkg.Name, ifc.Name)
+		}
+		for _, t := range pkg.Types {
+			assign(pkg.Name, t.Name)
+		}
+	}
+
+	renderFieldList := func(fields []FieldApi) []indexedField {
+		var out []indexedField
+		for _, f := range fields {
+			out = append(out, indexedField{Name: f.Name, Type: f.Type, TypeID: resolveTypeID(f.Type, typeIDs), Doc: f.Doc})
+		}
+		return out
+	}
+	renderMethods := func(methods []FuncApi) []indexedSignature {
+		var out []indexedSignature
+		for _, m := range methods {
+			sig := indexedSignature{Name: m.Name, Doc: m.Doc}
+			for _, p := range m.Params {
+				sig.Params = append(sig.Params, indexedTypedValue{Name: p.Name, Type: p.Type, TypeID: resolveTypeID(p.Type, typeIDs)})
+			}
+			for _, r := range m.Results {
+				sig.Results = append(sig.Results, indexedTypedValue{Name: r.Name, Type: r.Type, TypeID: resolveTypeID(r.Type, typeIDs)})
+			}
+			out = append(out, sig)
+		}
+		return out
+	}
+
+	for _, pkg := range api.Packages {
+		for _, s := range pkg.Structs {
+			types = append(types, indexedType{
+				ID: rowIDs[pkg.Name+"."+s.Name], Name: s.Name, Package: pkg.Name, Kind: "struct",
+				Doc: s.Doc, SourcePos: s.SourcePos, Fields: renderFieldList(s.Fields), Methods: renderMethods(s.Methods),
+			})
+		}
+		for _, ifc := range pkg.Interfaces {
+			types = append(types, indexedType{
+				ID: rowIDs[pkg.Name+"."+ifc.Name], Name: ifc.Name, Package: pkg.Name, Kind: "interface",
+				Doc: ifc.Doc, SourcePos: ifc.SourcePos, Methods: renderMethods(ifc.Methods),
+			})
+		}
+		for _, t := range pkg.Types {
+			types = append(types, indexedType{
+				ID: rowIDs[pkg.Name+"."+t.Name], Name: t.Name, Package: pkg.Name, Kind: "type",
+				Doc: t.Doc, SourcePos: t.SourcePos, Underlying: t.Type,
+			})
+		}
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].ID < types[j].ID })
+
+	payload := struct {
+		exportHeader
+		Types []indexedType `json:"types"`
+	}{exportHeader: newExportHeader(api.Package), Types: types}
+	return json.Marshal(payload)
+}
+
 func formatStubs(api *ApiIndex) string {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("// %s - Public API Surface\n", api.Package))
@@ -1962,6 +4999,7 @@ func formatStubs(api *ApiIndex) string {
 			if c.Doc != "" {
 				sb.WriteString(fmt.Sprintf("// %s\n", c.Doc))
 			}
+			sb.WriteString(buildConstraintComment(c.Contexts))
 			if c.Type != "" {
 				sb.WriteString(fmt.Sprintf("const %s %s = %s\n", c.Name, c.Type, c.Value))
 			} else {
@@ -1999,6 +5037,7 @@ func formatStubs(api *ApiIndex) string {
 			if i.Doc != "" {
 				sb.WriteString(fmt.Sprintf("// %s\n", i.Doc))
 			}
+			sb.WriteString(buildConstraintComment(i.Contexts))
 			sb.WriteString(fmt.Sprintf("type %s interface {\n", i.Name))
 			for _, m := range i.Methods {
 				ret := ""
@@ -2015,6 +5054,10 @@ func formatStubs(api *ApiIndex) string {
 			if s.Doc != "" {
 				sb.WriteString(fmt.Sprintf("// %s\n", s.Doc))
 			}
+			sb.WriteString(buildConstraintComment(s.Contexts))
+			if len(s.Implements) > 0 {
+				sb.WriteString(fmt.Sprintf("// implements: %s\n", strings.Join(s.Implements, ", ")))
+			}
 			sb.WriteString(fmt.Sprintf("type %s struct {\n", s.Name))
 			for _, f := range s.Fields {
 				sb.WriteString(fmt.Sprintf("    %s %s\n", f.Name, f.Type))
@@ -2035,6 +5078,7 @@ func formatStubs(api *ApiIndex) string {
 			if f.Doc != "" {
 				sb.WriteString(fmt.Sprintf("// %s\n", f.Doc))
 			}
+			sb.WriteString(buildConstraintComment(f.Contexts))
 			ret := ""
 			if f.Ret != "" {
 				ret = " " + f.Ret
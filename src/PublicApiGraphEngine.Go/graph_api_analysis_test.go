@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// runJSON runs bin against rootPath with --json (plus any extra flags) and
+// decodes the result as generic JSON, the same way runFormat does above —
+// graph_api.go's //go:build ignore tag keeps its types (ApiIndex,
+// PackageApi, ...) out of this package, so these tests drive the tool as a
+// black box and walk the decoded map instead of referencing those types
+// directly.
+func runJSON(t *testing.T, bin, rootPath string, extraArgs ...string) map[string]interface{} {
+	t.Helper()
+	args := append([]string{rootPath, "--json"}, extraArgs...)
+	cmd := exec.Command(bin, args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("running graph_api %v: %v\n%s", args, err, out)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatalf("decoding --json output: %v\n%s", err, out)
+	}
+	return decoded
+}
+
+// findPackage returns the entry named name from idx["packages"].
+func findPackage(t *testing.T, idx map[string]interface{}, name string) map[string]interface{} {
+	t.Helper()
+	for _, p := range idx["packages"].([]interface{}) {
+		pkg := p.(map[string]interface{})
+		if pkg["name"] == name {
+			return pkg
+		}
+	}
+	t.Fatalf("package %q not found in %+v", name, idx["packages"])
+	return nil
+}
+
+// findByName returns the entry named name from a []interface{} of decoded
+// objects such as pkg["interfaces"] or pkg["structs"], or nil if absent.
+func findByName(list interface{}, name string) map[string]interface{} {
+	if list == nil {
+		return nil
+	}
+	for _, e := range list.([]interface{}) {
+		entry := e.(map[string]interface{})
+		if entry["name"] == name {
+			return entry
+		}
+	}
+	return nil
+}
+
+// stringSlice converts a decoded JSON array field (or nil) to []string.
+func stringSlice(v interface{}) []string {
+	if v == nil {
+		return nil
+	}
+	raw := v.([]interface{})
+	out := make([]string, len(raw))
+	for i, e := range raw {
+		out[i] = e.(string)
+	}
+	return out
+}
+
+// TestBuildContextMatrixTracksPlatformSpecificIface checks that extracting
+// across a build-context matrix annotates an interface declared only under
+// a windows.go-suffixed file with the contexts it was actually observed
+// under, while a portable struct comes out with no Contexts at all.
+func TestBuildContextMatrixTracksPlatformSpecificIface(t *testing.T) {
+	bin := buildGraphAPI(t)
+	idx := runJSON(t, bin, "testdata/buildcontext", "--contexts", "linux/amd64,windows/amd64")
+	pkg := findPackage(t, idx, "buildcontext")
+
+	handle := findByName(pkg["interfaces"], "Handle")
+	if handle == nil {
+		t.Fatalf("interface Handle not found in %+v", pkg["interfaces"])
+	}
+	if got := stringSlice(handle["contexts"]); len(got) != 1 || got[0] != "windows/amd64" {
+		t.Errorf("Handle.contexts = %v, want [\"windows/amd64\"]", got)
+	}
+
+	widget := findByName(pkg["structs"], "Widget")
+	if widget == nil {
+		t.Fatalf("struct Widget not found in %+v", pkg["structs"])
+	}
+	if got := stringSlice(widget["contexts"]); len(got) != 0 {
+		t.Errorf("Widget.contexts = %v, want empty (portable across the whole requested matrix)", got)
+	}
+}
+
+// extractToFile runs bin against rootPath with --json and writes the raw
+// output to a file under t.TempDir(), returning its path — the shape
+// --diff's readApiIndexFile expects, since --diff takes two snapshot files
+// rather than two source trees.
+func extractToFile(t *testing.T, bin, rootPath, fileName string) string {
+	t.Helper()
+	cmd := exec.Command(bin, rootPath, "--json")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("running graph_api %s --json: %v\n%s", rootPath, err, out)
+	}
+	path := filepath.Join(t.TempDir(), fileName)
+	if err := os.WriteFile(path, out, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestDiffDetectsBreakingRemoval drives graph_api's --diff subcommand
+// end-to-end against two extracted snapshots — testdata/diffold and
+// testdata/diffnew, which differ only by diffnew dropping Widget.Close —
+// and checks that Diff actually classifies the removal as breaking, rather
+// than just pinning the diff command's output shape.
+func TestDiffDetectsBreakingRemoval(t *testing.T) {
+	bin := buildGraphAPI(t)
+	oldFile := extractToFile(t, bin, "testdata/diffold", "old.json")
+	newFile := extractToFile(t, bin, "testdata/diffnew", "new.json")
+
+	cmd := exec.Command(bin, "--diff", oldFile, newFile, "--json")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatal("graph_api --diff exited 0, want a non-zero exit for a breaking change without --allow-breaking")
+	}
+
+	var result map[string]interface{}
+	if jerr := json.Unmarshal(out, &result); jerr != nil {
+		t.Fatalf("decoding --diff --json output: %v\n%s", jerr, out)
+	}
+
+	if isBreaking, _ := result["isBreaking"].(bool); !isBreaking {
+		t.Errorf("isBreaking = %v, want true", result["isBreaking"])
+	}
+
+	var removedClose bool
+	for _, c := range result["breaking"].([]interface{}) {
+		change := c.(map[string]interface{})
+		if change["kind"] == "removed" && change["symbol"] == "Close" && change["package"] == "api" {
+			removedClose = true
+		}
+	}
+	if !removedClose {
+		t.Errorf("breaking changes %+v did not include a \"removed\" entry for api.Close", result["breaking"])
+	}
+}
+
+// TestExtractPromotesEmbeddedMethods checks that a method reached only
+// through an embedded field (testdata/embedding's Derived embeds Base,
+// which declares Ping) is promoted onto the owning struct's Methods with
+// PromotedFrom naming the embed, and that Embeds itself lists the embedded
+// type.
+func TestExtractPromotesEmbeddedMethods(t *testing.T) {
+	bin := buildGraphAPI(t)
+	idx := runJSON(t, bin, "testdata/embedding")
+	pkg := findPackage(t, idx, "embedding")
+
+	derived := findByName(pkg["structs"], "Derived")
+	if derived == nil {
+		t.Fatalf("struct Derived not found in %+v", pkg["structs"])
+	}
+	if embeds := stringSlice(derived["embeds"]); len(embeds) != 1 || embeds[0] != "*Base" {
+		t.Errorf("Derived.embeds = %v, want [\"*Base\"]", embeds)
+	}
+
+	ping := findByName(derived["methods"], "Ping")
+	if ping == nil {
+		t.Fatalf("promoted method Ping not found in Derived.methods %+v", derived["methods"])
+	}
+	if ping["promotedFrom"] != "Base" {
+		t.Errorf("Ping.promotedFrom = %v, want %q", ping["promotedFrom"], "Base")
+	}
+}
+
+// TestExtractGenericConstraintAndTypeParam checks that a constraint
+// interface's type-set union (testdata/generics' Ordered) is broken out
+// into Constraint, and that a generic struct's own type parameter
+// (Set[T Ordered]) is broken out into TypeParamDetails naming that
+// constraint — not just recorded as opaque TypeParams strings.
+func TestExtractGenericConstraintAndTypeParam(t *testing.T) {
+	bin := buildGraphAPI(t)
+	idx := runJSON(t, bin, "testdata/generics")
+	pkg := findPackage(t, idx, "generics")
+
+	ordered := findByName(pkg["interfaces"], "Ordered")
+	if ordered == nil {
+		t.Fatalf("interface Ordered not found in %+v", pkg["interfaces"])
+	}
+	constraint, _ := ordered["constraint"].([]interface{})
+	if len(constraint) != 3 {
+		t.Fatalf("Ordered.constraint = %+v, want 3 union alternatives", constraint)
+	}
+	wantTypes := map[string]bool{"int": true, "float64": true, "string": true}
+	for _, e := range constraint {
+		elem := e.(map[string]interface{})
+		if approx, _ := elem["approx"].(bool); !approx {
+			t.Errorf("constraint elem %+v: approx = %v, want true (\"~\" marker)", elem, elem["approx"])
+		}
+		if !wantTypes[elem["type"].(string)] {
+			t.Errorf("unexpected constraint elem type %q", elem["type"])
+		}
+	}
+
+	set := findByName(pkg["structs"], "Set")
+	if set == nil {
+		t.Fatalf("struct Set not found in %+v", pkg["structs"])
+	}
+	details, _ := set["typeParamDetails"].([]interface{})
+	if len(details) != 1 {
+		t.Fatalf("Set.typeParamDetails = %+v, want 1 entry", details)
+	}
+	tp := details[0].(map[string]interface{})
+	if tp["name"] != "T" || tp["constraint"] != "Ordered" {
+		t.Errorf("Set's type param = %+v, want name=T constraint=Ordered", tp)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// buildGraphAPI compiles graph_api.go (which has a //go:build ignore tag
+// and so is never part of this package's normal build) into a throwaway
+// binary these tests can exec directly, the same way a user would run it
+// via `go build -o ... graph_api.go`.
+func buildGraphAPI(t *testing.T) string {
+	t.Helper()
+	bin := filepath.Join(t.TempDir(), "graph_api")
+	cmd := exec.Command("go", "build", "-o", bin, "graph_api.go")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("building graph_api.go: %v\n%s", err, out)
+	}
+	return bin
+}
+
+func runFormat(t *testing.T, bin, format string) map[string]interface{} {
+	t.Helper()
+	cmd := exec.Command(bin, "testdata/goldenformat", "--format", format, "--pretty")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("running graph_api --format=%s: %v\n%s", format, err, out.String())
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("decoding --format=%s output: %v\n%s", format, err, out.String())
+	}
+	return decoded
+}
+
+// goldenCompare diffs decoded against the golden file at goldenPath, after
+// sanitizing the "generator" field: it's a content hash of the built
+// binary (see computeToolVersion), so it changes on every rebuild and
+// can't be pinned byte-for-byte. Run with UPDATE_GOLDEN=1 to rewrite the
+// golden file after an intentional schema change.
+func goldenCompare(t *testing.T, decoded map[string]interface{}, goldenPath string) {
+	t.Helper()
+	decoded["generator"] = "sdk-chat-<sanitized>"
+	got, err := json.MarshalIndent(decoded, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+
+	if os.Getenv("UPDATE_GOLDEN") == "1" {
+		if err := os.WriteFile(goldenPath, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output for %s does not match golden (run with UPDATE_GOLDEN=1 after an intentional schema change); got:\n%s\nwant:\n%s", goldenPath, got, want)
+	}
+}
+
+// TestFormatJSONGolden pins --format=json's enveloped shape so a schema
+// change shows up as a diff here, forcing exportSchemaVersion to bump
+// alongside it.
+func TestFormatJSONGolden(t *testing.T) {
+	bin := buildGraphAPI(t)
+	decoded := runFormat(t, bin, "json")
+	goldenCompare(t, decoded, "testdata/goldenformat.json.golden")
+}
+
+// TestFormatIndexGolden is TestFormatJSONGolden's counterpart for
+// --format=index.
+func TestFormatIndexGolden(t *testing.T) {
+	bin := buildGraphAPI(t)
+	decoded := runFormat(t, bin, "index")
+	goldenCompare(t, decoded, "testdata/goldenformat.index.golden")
+}
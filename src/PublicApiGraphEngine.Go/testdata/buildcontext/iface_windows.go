@@ -0,0 +1,8 @@
+package buildcontext
+
+// Handle is declared only under GOOS=windows (via the _windows.go filename
+// suffix), so it must come out annotated with exactly the windows contexts
+// it was extracted under, never merged in as if it were portable.
+type Handle interface {
+	Close() error
+}
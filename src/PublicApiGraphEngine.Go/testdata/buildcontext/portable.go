@@ -0,0 +1,12 @@
+// Package buildcontext is a minimal fixture used by
+// TestBuildContextMatrixTracksPlatformSpecificIface to check that
+// extractPackageMatrix annotates a platform-specific interface with the
+// contexts it was actually observed under, instead of silently unioning it
+// in as portable.
+package buildcontext
+
+// Widget is declared with no build constraint, so it should come out of
+// every context with an empty Contexts (portable).
+type Widget struct {
+	Name string
+}
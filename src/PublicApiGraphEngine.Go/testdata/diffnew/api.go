@@ -0,0 +1,14 @@
+// Package api is the "after" snapshot fixture for
+// TestDiffDetectsBreakingRemoval: Widget.Close was removed compared to
+// testdata/diffold's copy of this package, which Diff must flag as breaking.
+package api
+
+// Widget is a simple exported type present in both snapshots.
+type Widget struct {
+	Name string
+}
+
+// Open returns a new Widget. Present unchanged in both snapshots.
+func Open(name string) *Widget {
+	return &Widget{Name: name}
+}
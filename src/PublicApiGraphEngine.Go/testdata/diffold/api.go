@@ -0,0 +1,19 @@
+// Package api is the "before" snapshot fixture for
+// TestDiffDetectsBreakingRemoval: testdata/diffnew's copy of this package
+// removes Close, a breaking change Diff must flag.
+package api
+
+// Widget is a simple exported type present in both snapshots.
+type Widget struct {
+	Name string
+}
+
+// Close releases w. Removed in the "new" snapshot.
+func (w *Widget) Close() error {
+	return nil
+}
+
+// Open returns a new Widget. Present unchanged in both snapshots.
+func Open(name string) *Widget {
+	return &Widget{Name: name}
+}
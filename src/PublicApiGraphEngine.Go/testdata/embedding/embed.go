@@ -0,0 +1,22 @@
+// Package embedding is a minimal fixture used by
+// TestExtractPromotesEmbeddedMethods to check that a method reached only
+// through an embedded field is promoted onto the owning struct with
+// PromotedFrom naming the embed, rather than being dropped.
+package embedding
+
+// Base has one exported method, promoted onto anything that embeds it.
+type Base struct {
+	ID string
+}
+
+// Ping is declared directly on Base.
+func (b *Base) Ping() string {
+	return b.ID
+}
+
+// Derived embeds Base by pointer, so it should inherit Ping as a promoted
+// method without declaring it itself.
+type Derived struct {
+	*Base
+	Extra string
+}
@@ -0,0 +1,21 @@
+// Package generics is a minimal fixture used by
+// TestExtractGenericConstraintAndTypeParam to check that a generic type's
+// TypeParamDetails and a constraint interface's type-set Constraint are
+// both extracted correctly.
+package generics
+
+// Ordered is a constraint interface listing a type-set union, the same
+// shape as constraints.Ordered.
+type Ordered interface {
+	~int | ~float64 | ~string
+}
+
+// Set is a generic struct parameterized over any Ordered type.
+type Set[T Ordered] struct {
+	items []T
+}
+
+// Add inserts v into s.
+func (s *Set[T]) Add(v T) {
+	s.items = append(s.items, v)
+}
@@ -0,0 +1,23 @@
+// Package goldenformat is a minimal fixture used by
+// TestFormatJSONGolden/TestFormatIndexGolden to pin the --format=json and
+// --format=index export shapes; changing either format's schema should
+// show up as a diff against the checked-in golden file here.
+package goldenformat
+
+// Widget is a simple struct with one field referencing another type in
+// the same package, exercising formatIndex's type cross-referencing.
+type Widget struct {
+	// Name is the widget's display name.
+	Name string
+	Kind WidgetKind
+}
+
+// WidgetKind classifies a Widget.
+type WidgetKind struct {
+	Label string
+}
+
+// Describe returns a human-readable summary of w.
+func (w *Widget) Describe() string {
+	return w.Name
+}
@@ -0,0 +1,121 @@
+package httputil
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestHandlerRoundTripperDispatchesToHandler checks that a RoundTripper
+// built from NewHandlerRoundTripper delivers requests directly to the
+// Handler in-process, without a network hop.
+func TestHandlerRoundTripperDispatchesToHandler(t *testing.T) {
+	var gotPath string
+	h := HandlerFunc(func(ctx context.Context, req *Request) (*Response, error) {
+		gotPath = req.Path
+		return &Response{StatusCode: 200, Body: []byte("ok")}, nil
+	})
+
+	rt := NewHandlerRoundTripper(h)
+	resp, err := rt.RoundTrip(context.Background(), &Request{Method: "GET", Path: "/widgets"})
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	if gotPath != "/widgets" {
+		t.Errorf("handler saw path %q, want %q", gotPath, "/widgets")
+	}
+	if resp.StatusCode != 200 || string(resp.Body) != "ok" {
+		t.Errorf("resp = %+v, want StatusCode=200 Body=ok", resp)
+	}
+}
+
+// TestRoundTripperHandlerIsTheInverse checks that NewRoundTripperHandler
+// round-trips a Handle call back through an arbitrary RoundTripper.
+func TestRoundTripperHandlerIsTheInverse(t *testing.T) {
+	rt := RoundTripperFunc(func(ctx context.Context, req *Request) (*Response, error) {
+		return &Response{StatusCode: 201, Body: req.Body}, nil
+	})
+
+	h := NewRoundTripperHandler(rt)
+	resp, err := h.Handle(context.Background(), &Request{Method: "POST", Body: []byte("payload")})
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if resp.StatusCode != 201 || string(resp.Body) != "payload" {
+		t.Errorf("resp = %+v, want StatusCode=201 Body=payload", resp)
+	}
+}
+
+// TestHandlerRoundTripperRoundTripperHandlerCompose checks that wrapping a
+// Handler in NewHandlerRoundTripper and back in NewRoundTripperHandler
+// still reaches the original handler.
+func TestHandlerRoundTripperRoundTripperHandlerCompose(t *testing.T) {
+	calls := 0
+	h := HandlerFunc(func(ctx context.Context, req *Request) (*Response, error) {
+		calls++
+		return &Response{StatusCode: 204}, nil
+	})
+
+	composed := NewRoundTripperHandler(NewHandlerRoundTripper(h))
+	if _, err := composed.Handle(context.Background(), &Request{}); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("handler called %d times, want 1", calls)
+	}
+}
+
+// TestRecordingRoundTripperCapturesExchanges checks that
+// RecordingRoundTripper records each request/response/error triple it
+// forwards, in order, so tests can assert against what was sent.
+func TestRecordingRoundTripperCapturesExchanges(t *testing.T) {
+	boom := errors.New("boom")
+	next := RoundTripperFunc(func(ctx context.Context, req *Request) (*Response, error) {
+		if req.Path == "/fail" {
+			return nil, boom
+		}
+		return &Response{StatusCode: 200}, nil
+	})
+
+	rec := NewRecordingRoundTripper(next, 0)
+	if _, err := rec.RoundTrip(context.Background(), &Request{Path: "/a"}); err != nil {
+		t.Fatalf("RoundTrip /a: %v", err)
+	}
+	if _, err := rec.RoundTrip(context.Background(), &Request{Path: "/fail"}); !errors.Is(err, boom) {
+		t.Fatalf("RoundTrip /fail: got err %v, want %v", err, boom)
+	}
+
+	exchanges := rec.Exchanges()
+	if len(exchanges) != 2 {
+		t.Fatalf("got %d exchanges, want 2", len(exchanges))
+	}
+	if exchanges[0].Request.Path != "/a" || exchanges[0].Err != nil {
+		t.Errorf("exchanges[0] = %+v, want Path=/a Err=nil", exchanges[0])
+	}
+	if exchanges[1].Request.Path != "/fail" || !errors.Is(exchanges[1].Err, boom) {
+		t.Errorf("exchanges[1] = %+v, want Path=/fail Err=%v", exchanges[1], boom)
+	}
+}
+
+// TestRecordingRoundTripperLimit checks that a positive Limit discards the
+// oldest exchanges once exceeded, keeping only the most recent Limit.
+func TestRecordingRoundTripperLimit(t *testing.T) {
+	next := RoundTripperFunc(func(ctx context.Context, req *Request) (*Response, error) {
+		return &Response{StatusCode: 200}, nil
+	})
+	rec := NewRecordingRoundTripper(next, 2)
+
+	for _, path := range []string{"/1", "/2", "/3"} {
+		if _, err := rec.RoundTrip(context.Background(), &Request{Path: path}); err != nil {
+			t.Fatalf("RoundTrip %s: %v", path, err)
+		}
+	}
+
+	exchanges := rec.Exchanges()
+	if len(exchanges) != 2 {
+		t.Fatalf("got %d exchanges, want 2 (Limit)", len(exchanges))
+	}
+	if exchanges[0].Request.Path != "/2" || exchanges[1].Request.Path != "/3" {
+		t.Errorf("got paths %q, %q, want /2, /3 (oldest dropped)", exchanges[0].Request.Path, exchanges[1].Request.Path)
+	}
+}